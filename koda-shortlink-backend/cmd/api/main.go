@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"koda-shortlink-backend/internal/config"
 	"koda-shortlink-backend/internal/database"
+	"koda-shortlink-backend/internal/geoip"
 	"koda-shortlink-backend/internal/handler"
+	"koda-shortlink-backend/internal/mailer"
 	"koda-shortlink-backend/internal/middleware"
+	"koda-shortlink-backend/internal/models"
 	"koda-shortlink-backend/internal/repository"
 	"koda-shortlink-backend/internal/service"
 	"koda-shortlink-backend/internal/utils"
@@ -56,23 +59,109 @@ func main() {
 	}
 	log.Println("Redis connection established")
 
+	geoipResolver, err := geoip.NewResolver(cfg.GeoIP.DatabasePath)
+	if err != nil {
+		log.Fatal("Failed to load GeoIP database:", err)
+	}
+	defer geoipResolver.Close()
+
+	mail, err := mailer.NewMailer(&cfg.Mail)
+	if err != nil {
+		log.Fatal("Failed to configure mailer:", err)
+	}
+
 	jwtUtil := utils.NewJWTUtil(cfg.JWT.Secret, cfg.JWT.AccessExpiry, cfg.JWT.RefreshExpiry)
+	if cfg.JWT.SigningKeys != "" {
+		signingKeys, err := utils.LoadSigningKeys(cfg.JWT.SigningKeys)
+		if err != nil {
+			log.Fatal("Failed to load JWT signing keys:", err)
+		}
+		jwtUtil.SetSigningKeys(signingKeys)
+		log.Printf("Loaded %d JWT signing keys", len(signingKeys))
+	} else if cfg.JWT.Secret == "your-secret-key" {
+		log.Println("WARNING: JWT_SECRET is using the default value, configure JWT_SIGNING_KEYS or JWT_SECRET")
+	}
+	tokenDenylist := utils.NewTokenDenylist(redisClient, max(cfg.JWT.AccessExpiry, service.LongestTokenExpiry))
+
+	err = utils.SetDefaultArgon2Params(&utils.Argon2Params{
+		Memory:      cfg.Argon2.Memory,
+		Iterations:  cfg.Argon2.Iterations,
+		Parallelism: cfg.Argon2.Parallelism,
+		SaltLength:  cfg.Argon2.SaltLength,
+		KeyLength:   cfg.Argon2.KeyLength,
+	})
+	if err != nil {
+		log.Fatal("Invalid Argon2 configuration:", err)
+	}
+
+	passwordPolicy := &utils.PasswordPolicy{
+		MinLength:            cfg.Password.MinLength,
+		MaxLength:            cfg.Password.MaxLength,
+		RequireUppercase:     cfg.Password.RequireUppercase,
+		RequireLowercase:     cfg.Password.RequireLowercase,
+		RequireDigit:         cfg.Password.RequireDigit,
+		RequireSymbol:        cfg.Password.RequireSymbol,
+		DisallowPersonalInfo: cfg.Password.DisallowPersonalInfo,
+	}
+	if cfg.Password.BreachedListPath != "" {
+		breachedList, err := utils.OpenBreachedPasswordList(cfg.Password.BreachedListPath)
+		if err != nil {
+			log.Fatal("Failed to open breached password list:", err)
+		}
+		defer breachedList.Close()
+		passwordPolicy.Breached = breachedList
+		log.Println("Breached password check enabled")
+	}
 
 	userRepo := repository.NewUserRepository(db.DB)
 	linkRepo := repository.NewShortLinkRepository(db.DB)
 	sessionRepo := repository.NewSessionRepository(db.DB)
 	clickRepo := repository.NewClickRepository(db.DB)
+	oauthRepo := repository.NewOAuthAccountRepository(db.DB)
+	apiKeyRepo := repository.NewAPIKeyRepository(db.DB)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db.DB)
+	workspaceRepo := repository.NewWorkspaceRepository(db.DB)
+	tagRepo := repository.NewTagRepository(db.DB)
+	folderRepo := repository.NewFolderRepository(db.DB)
+
+	loginThrottle := service.NewLoginThrottle(redisClient)
 
-	authService := service.NewAuthService(userRepo, sessionRepo, jwtUtil)
-	linkService := service.NewLinkService(linkRepo, clickRepo, redisClient, cfg.Server.BaseURL)
+	authService := service.NewAuthService(userRepo, sessionRepo, jwtUtil, tokenDenylist, loginThrottle, passwordPolicy)
+	userService := service.NewUserService(userRepo, sessionRepo, tokenDenylist, passwordPolicy)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo)
+	twoFactorService := service.NewTwoFactorService(authService, userRepo, recoveryCodeRepo, jwtUtil, tokenDenylist, loginThrottle, redisClient)
+	verificationService := service.NewVerificationService(userRepo, sessionRepo, jwtUtil, tokenDenylist, mail, loginThrottle, passwordPolicy, cfg.Server.FrontendURL)
+	oauthService := service.NewOAuthService(authService, userRepo, oauthRepo, redisClient, &cfg.OAuth)
+	authorizer := service.NewAuthorizer(workspaceRepo)
+	linkService := service.NewLinkService(linkRepo, clickRepo, tagRepo, folderRepo, authorizer, redisClient, cfg.Server.BaseURL)
+	workspaceService := service.NewWorkspaceService(workspaceRepo, userRepo, linkService, authorizer)
+	tagService := service.NewTagService(tagRepo, linkRepo, authorizer)
+	folderService := service.NewFolderService(folderRepo, authorizer)
+	adminService := service.NewAdminService(userRepo, linkRepo, userService, linkService, cfg.Server.BaseURL)
+	accountService := service.NewAccountService(userRepo, linkRepo, clickRepo, workspaceRepo, linkService, verificationService, tokenDenylist, cfg.Account.DeletionGracePeriod, cfg.Server.BaseURL)
 
-	authHandler := handler.NewAuthHandler(authService)
+	authHandler := handler.NewAuthHandler(authService, oauthService, twoFactorService, verificationService)
+	userHandler := handler.NewUserHandler(userService, accountService)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
 	linkHandler := handler.NewLinkHandler(linkService)
-	redirectHandler := handler.NewRedirectHandler(linkService, redisClient)
+	adminHandler := handler.NewAdminHandler(adminService)
+	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
+	tagHandler := handler.NewTagHandler(tagService)
+	folderHandler := handler.NewFolderHandler(folderService)
+	wellKnownHandler := handler.NewWellKnownHandler(jwtUtil)
+	redirectHandler := handler.NewRedirectHandler(linkService, redisClient, geoipResolver)
 
-	authMiddleware := middleware.NewAuthMiddleware(jwtUtil)
+	authMiddleware := middleware.NewAuthMiddleware(jwtUtil, tokenDenylist, apiKeyService, userService)
 	rateLimiter := middleware.NewRateLimiter(redisClient, 100, time.Minute)
 
+	accountService.StartPurgeScheduler(time.Hour)
+	go func() {
+		if err := linkService.BackfillUniqueVisitors(); err != nil {
+			log.Printf("Failed to backfill unique visitors: %v", err)
+		}
+	}()
+	authService.StartSessionCleanup(time.Hour)
+
 	if cfg.Server.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -83,6 +172,7 @@ func main() {
 	router.Use(middleware.CORS())
 
 	router.GET("/:shortCode", redirectHandler.Redirect)
+	router.GET("/.well-known/jwks.json", wellKnownHandler.JWKS)
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "healthy"})
 	})
@@ -94,26 +184,114 @@ func main() {
 	{
 		auth.POST("/register", authHandler.Register)
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/login/2fa", authHandler.LoginTwoFactor)
 		auth.POST("/refresh", authHandler.RefreshToken)
 		auth.POST("/logout", authMiddleware.RequireAuth(), authHandler.Logout)
+		auth.POST("/logout-all", authMiddleware.RequireAuth(), authHandler.LogoutAll)
+		auth.GET("/sessions", authMiddleware.RequireAuth(), authHandler.GetSessions)
+		auth.DELETE("/sessions/:id", authMiddleware.RequireAuth(), authHandler.RevokeSession)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", authMiddleware.RequireAuth(), rateLimiter.LimitByEndpoint(3, time.Hour), authHandler.ResendVerificationEmail)
+		auth.POST("/forgot-password", rateLimiter.LimitByEndpoint(5, time.Hour), authHandler.ForgotPassword)
+		auth.POST("/reset-password", rateLimiter.LimitByEndpoint(10, time.Hour), authHandler.ResetPassword)
+		auth.GET("/google", authHandler.GoogleLogin)
+		auth.GET("/google/callback", authHandler.GoogleCallback)
+	}
 
+	twoFactor := auth.Group("/2fa")
+	twoFactor.Use(authMiddleware.RequireAuth())
+	{
+		twoFactor.POST("/setup", authHandler.SetupTwoFactor)
+		twoFactor.POST("/enable", authHandler.EnableTwoFactor)
+		twoFactor.POST("/disable", authHandler.DisableTwoFactor)
+		twoFactor.POST("/recovery-codes", authHandler.RegenerateRecoveryCodes)
+	}
+
+	users := api.Group("/users")
+	users.Use(authMiddleware.RequireAuth())
+	{
+		users.GET("/me", userHandler.GetProfile)
+		users.PUT("/me", userHandler.UpdateProfile)
+		users.DELETE("/me", userHandler.DeleteAccount)
+		users.POST("/me/delete-confirmation", rateLimiter.LimitByEndpoint(3, time.Hour), userHandler.RequestDeletionConfirmation)
+		users.POST("/me/password", userHandler.ChangePassword)
+		users.GET("/me/export", rateLimiter.LimitByEndpoint(5, time.Hour), userHandler.ExportData)
 	}
 
 	links := api.Group("/links")
 	{
-		links.POST("", authMiddleware.OptionalAuth(), linkHandler.CreateLink)
-		links.GET("", authMiddleware.RequireAuth(), linkHandler.GetUserLinks)
-		links.GET("/:shortCode", authMiddleware.RequireAuth(), linkHandler.GetLinkByShortCode)
-		links.PUT("/:shortCode", authMiddleware.RequireAuth(), linkHandler.UpdateLink)
-		links.DELETE("/:shortCode", authMiddleware.RequireAuth(), linkHandler.DeleteLink)
+		links.POST("", authMiddleware.OptionalAuth(models.ScopeLinksWrite), linkHandler.CreateLink)
+		links.POST("/bulk", authMiddleware.RequireAuth(models.ScopeLinksWrite), linkHandler.BulkCreateLinks)
+		links.GET("", authMiddleware.RequireAuth(models.ScopeLinksRead), linkHandler.GetUserLinks)
+		links.GET("/:shortCode", authMiddleware.RequireAuth(models.ScopeLinksRead), linkHandler.GetLinkByShortCode)
+		links.GET("/:shortCode/analytics", authMiddleware.RequireAuth(models.ScopeAnalyticsRead), linkHandler.GetLinkAnalytics)
+		links.GET("/:shortCode/clicks", authMiddleware.RequireAuth(models.ScopeAnalyticsRead), linkHandler.GetLinkClicks)
+		links.PUT("/:shortCode/tags", authMiddleware.RequireAuth(models.ScopeLinksWrite), tagHandler.SetLinkTags)
+		links.PUT("/:shortCode", authMiddleware.RequireAuth(models.ScopeLinksWrite), linkHandler.UpdateLink)
+		links.DELETE("/:shortCode", authMiddleware.RequireAuth(models.ScopeLinksWrite), linkHandler.DeleteLink)
+	}
+
+	apiKeys := api.Group("/api-keys")
+	apiKeys.Use(authMiddleware.RequireAuth())
+	{
+		apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+		apiKeys.GET("", apiKeyHandler.GetAPIKeys)
+		apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+	}
+
+	tags := api.Group("/tags")
+	{
+		tags.POST("", authMiddleware.RequireAuth(models.ScopeLinksWrite), tagHandler.CreateTag)
+		tags.GET("", authMiddleware.RequireAuth(models.ScopeLinksRead), tagHandler.GetTags)
+		tags.PUT("/:id", authMiddleware.RequireAuth(models.ScopeLinksWrite), tagHandler.UpdateTag)
+		tags.DELETE("/:id", authMiddleware.RequireAuth(models.ScopeLinksWrite), tagHandler.DeleteTag)
+	}
+
+	folders := api.Group("/folders")
+	{
+		folders.POST("", authMiddleware.RequireAuth(models.ScopeLinksWrite), folderHandler.CreateFolder)
+		folders.GET("", authMiddleware.RequireAuth(models.ScopeLinksRead), folderHandler.GetFolders)
+		folders.PUT("/:id", authMiddleware.RequireAuth(models.ScopeLinksWrite), folderHandler.UpdateFolder)
+		folders.DELETE("/:id", authMiddleware.RequireAuth(models.ScopeLinksWrite), folderHandler.DeleteFolder)
+	}
+
+	exports := api.Group("/exports")
+	{
+		exports.GET("/links", authMiddleware.RequireAuth(models.ScopeLinksRead), rateLimiter.LimitByEndpoint(10, time.Hour), linkHandler.ExportLinks)
+		exports.GET("/clicks", authMiddleware.RequireAuth(models.ScopeAnalyticsRead), rateLimiter.LimitByEndpoint(10, time.Hour), linkHandler.ExportClicks)
+	}
+
+	workspaces := api.Group("/workspaces")
+	workspaces.Use(authMiddleware.RequireAuth())
+	{
+		workspaces.POST("", workspaceHandler.CreateWorkspace)
+		workspaces.GET("", workspaceHandler.GetWorkspaces)
+		workspaces.GET("/:id", workspaceHandler.GetWorkspace)
+		workspaces.PUT("/:id", workspaceHandler.UpdateWorkspace)
+		workspaces.DELETE("/:id", workspaceHandler.DeleteWorkspace)
+		workspaces.GET("/:id/members", workspaceHandler.GetMembers)
+		workspaces.POST("/:id/members", workspaceHandler.AddMember)
+		workspaces.PUT("/:id/members/:userId", workspaceHandler.UpdateMember)
+		workspaces.DELETE("/:id/members/:userId", workspaceHandler.RemoveMember)
 	}
 
 	dashboard := api.Group("/dashboard")
-	dashboard.Use(authMiddleware.RequireAuth())
+	dashboard.Use(authMiddleware.RequireAuth(models.ScopeAnalyticsRead))
 	{
 		dashboard.GET("/stats", linkHandler.GetDashboardStats)
 	}
 
+	admin := api.Group("/admin")
+	admin.Use(authMiddleware.RequireAuth(), authMiddleware.RequireRole(models.RoleAdmin))
+	{
+		admin.GET("/users", adminHandler.ListUsers)
+		admin.GET("/users/:id", adminHandler.GetUser)
+		admin.PUT("/users/:id/status", adminHandler.SetUserActive)
+		admin.GET("/links", adminHandler.ListLinks)
+		admin.GET("/links/:shortCode", adminHandler.GetLink)
+		admin.PUT("/links/:shortCode/status", adminHandler.SetLinkActive)
+	}
+
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 	log.Printf("Server starting on %s", addr)
 	if err := router.Run(addr); err != nil {
@@ -0,0 +1,50 @@
+// Command grant-admin gives an existing account the admin role, or takes it
+// away with -revoke. The API never hands out roles, so this is how the first
+// administrator is created:
+//
+//	go run ./cmd/grant-admin -email admin@example.com
+package main
+
+import (
+	"flag"
+	"koda-shortlink-backend/internal/config"
+	"koda-shortlink-backend/internal/database"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"log"
+	"strings"
+)
+
+func main() {
+	email := flag.String("email", "", "email address of the account")
+	revoke := flag.Bool("revoke", false, "demote the account back to a regular user")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		log.Fatal("-email is required")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	db, err := database.NewDatabase(&cfg.Database)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	role := models.RoleAdmin
+	if *revoke {
+		role = models.RoleUser
+	}
+
+	userRepo := repository.NewUserRepository(db.DB)
+	if err := userRepo.UpdateRoleByEmail(strings.TrimSpace(*email), role); err != nil {
+		log.Fatalf("Failed to set role of %s: %v", *email, err)
+	}
+
+	log.Printf("Set role of %s to %s", *email, role)
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -16,12 +18,18 @@ type Config struct {
 	Redis    RedisConfig
 	JWT      JWTConfig
 	OAuth    OAuthConfig
+	GeoIP    GeoIPConfig
+	Mail     MailConfig
+	Password PasswordConfig
+	Argon2   Argon2Config
+	Account  AccountConfig
 }
 
 type ServerConfig struct {
-	Port    string
-	Env     string
-	BaseURL string
+	Port        string
+	Env         string
+	BaseURL     string
+	FrontendURL string
 }
 
 type DatabaseConfig struct {
@@ -44,12 +52,53 @@ type JWTConfig struct {
 	Secret        string
 	AccessExpiry  time.Duration
 	RefreshExpiry time.Duration
+	SigningKeys   string
 }
 
 type OAuthConfig struct {
 	GoogleClientID     string
 	GoogleClientSecret string
 	GoogleRedirectURL  string
+	GoogleAuthURL      string
+	GoogleTokenURL     string
+	GoogleUserInfoURL  string
+}
+
+type GeoIPConfig struct {
+	DatabasePath string
+}
+
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	LogFile      string
+}
+
+type PasswordConfig struct {
+	MinLength            int
+	MaxLength            int
+	RequireUppercase     bool
+	RequireLowercase     bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	DisallowPersonalInfo bool
+	BreachedListPath     string
+}
+
+type Argon2Config struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration
 }
 
 func LoadConfig() (*Config, error) {
@@ -60,12 +109,33 @@ func LoadConfig() (*Config, error) {
 	accessExpiry, _ := time.ParseDuration(getEnv("JWT_ACCESS_EXPIRY", "15m"))
 	refreshExpiry, _ := time.ParseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h"))
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	deletionGracePeriod, err := getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", "720h")
+	if err != nil {
+		return nil, err
+	}
+	passwordMinLength, err := getEnvPositiveInt("PASSWORD_MIN_LENGTH", "8")
+	if err != nil {
+		return nil, err
+	}
+	passwordMaxLength, err := getEnvPositiveInt("PASSWORD_MAX_LENGTH", "128")
+	if err != nil {
+		return nil, err
+	}
+	if passwordMinLength > passwordMaxLength {
+		return nil, fmt.Errorf("invalid PASSWORD_MIN_LENGTH: must not exceed PASSWORD_MAX_LENGTH (%d)", passwordMaxLength)
+	}
+	argon2Memory, _ := strconv.ParseUint(getEnv("ARGON2_MEMORY_KB", "65536"), 10, 32)
+	argon2Iterations, _ := strconv.ParseUint(getEnv("ARGON2_ITERATIONS", "3"), 10, 32)
+	argon2Parallelism, _ := strconv.ParseUint(getEnv("ARGON2_PARALLELISM", "2"), 10, 8)
+	argon2SaltLength, _ := strconv.ParseUint(getEnv("ARGON2_SALT_LENGTH", "16"), 10, 32)
+	argon2KeyLength, _ := strconv.ParseUint(getEnv("ARGON2_KEY_LENGTH", "32"), 10, 32)
 
 	config := &Config{
 		Server: ServerConfig{
-			Port:    getEnv("SERVER_PORT", "8080"),
-			Env:     getEnv("APP_ENV", "development"),
-			BaseURL: getEnv("BASE_URL", "http://localhost:8080"),
+			Port:        getEnv("SERVER_PORT", "8080"),
+			Env:         getEnv("APP_ENV", "development"),
+			BaseURL:     getEnv("BASE_URL", "http://localhost:8080"),
+			FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -85,31 +155,112 @@ func LoadConfig() (*Config, error) {
 			Secret:        getEnv("JWT_SECRET", "your-secret-key"),
 			AccessExpiry:  accessExpiry,
 			RefreshExpiry: refreshExpiry,
+			SigningKeys:   getEnv("JWT_SIGNING_KEYS", ""),
 		},
 		OAuth: OAuthConfig{
 			GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
 			GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
 			GoogleRedirectURL:  getEnv("GOOGLE_REDIRECT_URL", ""),
+			GoogleAuthURL:      getEnv("GOOGLE_AUTH_URL", "https://accounts.google.com/o/oauth2/v2/auth"),
+			GoogleTokenURL:     getEnv("GOOGLE_TOKEN_URL", "https://oauth2.googleapis.com/token"),
+			GoogleUserInfoURL:  getEnv("GOOGLE_USERINFO_URL", "https://openidconnect.googleapis.com/v1/userinfo"),
+		},
+		GeoIP: GeoIPConfig{
+			DatabasePath: getEnv("GEOIP_DB_PATH", ""),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "Koda Shortlink <no-reply@localhost>"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			LogFile:      getEnv("MAIL_LOG_FILE", ""),
+		},
+		Password: PasswordConfig{
+			MinLength:            passwordMinLength,
+			MaxLength:            passwordMaxLength,
+			RequireUppercase:     getEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase:     getEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:         getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:        getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			DisallowPersonalInfo: getEnvBool("PASSWORD_DISALLOW_PERSONAL_INFO", true),
+			BreachedListPath:     getEnv("PASSWORD_BREACHED_LIST_PATH", ""),
+		},
+		Argon2: Argon2Config{
+			Memory:      uint32(argon2Memory),
+			Iterations:  uint32(argon2Iterations),
+			Parallelism: uint8(argon2Parallelism),
+			SaltLength:  uint32(argon2SaltLength),
+			KeyLength:   uint32(argon2KeyLength),
+		},
+		Account: AccountConfig{
+			DeletionGracePeriod: deletionGracePeriod,
 		},
 	}
 
 	return config, nil
 }
 
+// DSN pins the session time zone to UTC so DATE(clicked_at) and the
+// timestamps the app passes in agree on where a day starts.
 func (c *DatabaseConfig) DSN() string {
-	if url := os.Getenv("DATABASE_URL"); url != "" {
-		return url
+	if dsn := os.Getenv("DATABASE_URL"); dsn != "" {
+		return withUTCTimezone(dsn)
 	}
 
 	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode,
 	)
 }
 
+func withUTCTimezone(dsn string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return dsn
+		}
+		query := parsed.Query()
+		if query.Get("timezone") == "" {
+			query.Set("timezone", "UTC")
+			parsed.RawQuery = query.Encode()
+		}
+		return parsed.String()
+	}
+
+	if strings.Contains(dsn, "timezone=") {
+		return dsn
+	}
+	return dsn + " timezone=UTC"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key, defaultValue string) (time.Duration, error) {
+	value, err := time.ParseDuration(getEnv(key, defaultValue))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid %s: must be a non-negative duration such as 720h", key)
+	}
+	return value, nil
+}
+
+func getEnvPositiveInt(key, defaultValue string) (int, error) {
+	value, err := strconv.Atoi(getEnv(key, defaultValue))
+	if err != nil || value < 1 {
+		return 0, fmt.Errorf("invalid %s: must be a positive integer", key)
+	}
+	return value, nil
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
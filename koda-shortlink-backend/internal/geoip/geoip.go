@@ -0,0 +1,86 @@
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+type Location struct {
+	Country string
+	City    string
+}
+
+type Resolver interface {
+	Lookup(ip string) (*Location, error)
+	Close() error
+}
+
+func NewResolver(databasePath string) (Resolver, error) {
+	if databasePath == "" {
+		return NoopResolver{}, nil
+	}
+
+	return NewMaxMindResolver(databasePath)
+}
+
+type NoopResolver struct{}
+
+func (NoopResolver) Lookup(ip string) (*Location, error) {
+	return &Location{}, nil
+}
+
+func (NoopResolver) Close() error {
+	return nil
+}
+
+type MaxMindResolver struct {
+	reader *maxminddb.Reader
+}
+
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+func NewMaxMindResolver(databasePath string) (*MaxMindResolver, error) {
+	reader, err := maxminddb.Open(databasePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening geoip database: %w", err)
+	}
+
+	return &MaxMindResolver{reader: reader}, nil
+}
+
+func (r *MaxMindResolver) Lookup(ip string) (*Location, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, errors.New("invalid ip address")
+	}
+
+	var record mmdbRecord
+	if err := r.reader.Lookup(parsed, &record); err != nil {
+		return nil, err
+	}
+
+	location := &Location{
+		Country: record.Country.Names["en"],
+		City:    record.City.Names["en"],
+	}
+	if location.Country == "" {
+		location.Country = record.Country.ISOCode
+	}
+
+	return location, nil
+}
+
+func (r *MaxMindResolver) Close() error {
+	return r.reader.Close()
+}
@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"koda-shortlink-backend/internal/middleware"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/service"
+	"koda-shortlink-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// @Summary List users
+// @Description Search users by email or name, optionally filtered by role and status
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Search term"
+// @Param role query string false "Role: user or admin"
+// @Param is_active query bool false "Account status"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page" default(10)
+// @Success 200 {object} response.Response{data=models.AdminUserListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	isActive, err := optionalBoolQuery(c, "is_active")
+	if err != nil {
+		response.BadRequest(c, "Invalid is_active value", nil)
+		return
+	}
+
+	filter := &models.UserFilter{
+		Query:    c.Query("q"),
+		Role:     c.Query("role"),
+		IsActive: isActive,
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	users, err := h.adminService.ListUsers(filter, page, pageSize)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Users retrieved successfully", users)
+}
+
+// @Summary Get user
+// @Description Get any user by ID
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} response.Response{data=models.AdminUserResponse}
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	user, err := h.adminService.GetUser(userID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.OK(c, "User retrieved successfully", user)
+}
+
+// @Summary Activate or deactivate user
+// @Description Deactivating a user revokes all of their sessions and access tokens
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param request body models.SetActiveRequest true "Account status"
+// @Success 200 {object} response.Response{data=models.AdminUserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/admin/users/{id}/status [put]
+func (h *AdminHandler) SetUserActive(c *gin.Context) {
+	var req models.SetActiveRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	user, err := h.adminService.SetUserActive(adminID, userID, *req.IsActive)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "User status updated successfully", user)
+}
+
+// @Summary List links
+// @Description Search links of all users by short code, destination or title
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "Search term"
+// @Param user_id query int false "Owner user ID"
+// @Param is_active query bool false "Link status"
+// @Param blocked query bool false "Disabled by an administrator"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page" default(10)
+// @Success 200 {object} response.Response{data=models.AdminLinkListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/admin/links [get]
+func (h *AdminHandler) ListLinks(c *gin.Context) {
+	isActive, err := optionalBoolQuery(c, "is_active")
+	if err != nil {
+		response.BadRequest(c, "Invalid is_active value", nil)
+		return
+	}
+
+	blocked, err := optionalBoolQuery(c, "blocked")
+	if err != nil {
+		response.BadRequest(c, "Invalid blocked value", nil)
+		return
+	}
+
+	filter := &models.LinkFilter{
+		Query:    c.Query("q"),
+		IsActive: isActive,
+		Blocked:  blocked,
+	}
+
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid user_id value", nil)
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	links, err := h.adminService.ListLinks(filter, page, pageSize)
+	if err != nil {
+		response.InternalServerError(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Links retrieved successfully", links)
+}
+
+// @Summary Get link
+// @Description Get any link by short code, including its owner
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Success 200 {object} response.Response{data=models.AdminLinkResponse}
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/admin/links/{shortCode} [get]
+func (h *AdminHandler) GetLink(c *gin.Context) {
+	link, err := h.adminService.GetLink(c.Param("shortCode"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.OK(c, "Link retrieved successfully", link)
+}
+
+// @Summary Enable or disable link
+// @Description Disable any link for abuse handling, or lift the block. A blocked link cannot be re-enabled by its owner.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Param request body models.SetActiveRequest true "Link status"
+// @Success 200 {object} response.Response{data=models.AdminLinkResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/admin/links/{shortCode}/status [put]
+func (h *AdminHandler) SetLinkActive(c *gin.Context) {
+	var req models.SetActiveRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	adminID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	link, err := h.adminService.SetLinkBlocked(adminID, c.Param("shortCode"), !*req.IsActive)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Link status updated successfully", link)
+}
+
+func optionalBoolQuery(c *gin.Context, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"koda-shortlink-backend/internal/middleware"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/service"
+	"koda-shortlink-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// @Summary Create API key
+// @Description Create a personal API key. The key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateAPIKeyRequest true "API key data"
+// @Success 201 {object} response.Response{data=models.APIKeyResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	apiKey, err := h.apiKeyService.CreateAPIKey(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "API key created successfully", apiKey)
+}
+
+// @Summary List API keys
+// @Description List the active API keys of the authenticated user
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.APIKeyResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	apiKeys, err := h.apiKeyService.GetAPIKeys(userID)
+	if err != nil {
+		response.InternalServerError(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "API keys retrieved successfully", apiKeys)
+}
+
+// @Summary Revoke API key
+// @Description Revoke one of the authenticated user's API keys
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API key ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	keyID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid API key ID", nil)
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(userID, keyID); err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.OK(c, "API key revoked successfully", nil)
+}
@@ -1,20 +1,40 @@
 package handler
 
 import (
+	"errors"
+	"koda-shortlink-backend/internal/middleware"
 	"koda-shortlink-backend/internal/models"
 	"koda-shortlink-backend/internal/service"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
 
 	"koda-shortlink-backend/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
+// oauthStateCookie carries the OAuth state from GoogleLogin to GoogleCallback.
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/api/v1/auth/google"
+)
+
 type AuthHandler struct {
-	authService *service.AuthService
+	authService         *service.AuthService
+	oauthService        *service.OAuthService
+	twoFactorService    *service.TwoFactorService
+	verificationService *service.VerificationService
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *service.AuthService, oauthService *service.OAuthService, twoFactorService *service.TwoFactorService, verificationService *service.VerificationService) *AuthHandler {
+	return &AuthHandler{
+		authService:         authService,
+		oauthService:        oauthService,
+		twoFactorService:    twoFactorService,
+		verificationService: verificationService,
+	}
 }
 
 // @Summary Register a new user
@@ -34,17 +54,23 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := h.authService.Register(&req)
+	authResponse, err := h.authService.Register(&req, clientInfo(c))
 	if err != nil {
 		response.BadRequest(c, err.Error(), nil)
 		return
 	}
 
+	go func(userID int64) {
+		if err := h.verificationService.SendEmailVerification(userID); err != nil {
+			log.Printf("Failed to send verification email: %v", err)
+		}
+	}(authResponse.User.ID)
+
 	response.Created(c, "Registration successful", authResponse)
 }
 
 // @Summary Login user
-// @Description Authenticate user and return tokens
+// @Description Authenticate user and return tokens, or a challenge token when two-factor authentication is enabled
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -52,6 +78,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Success 200 {object} response.Response{data=models.AuthResponse}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.UserLoginRequest
@@ -61,15 +88,179 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	authResponse, err := h.authService.Login(&req)
+	authResponse, err := h.authService.Login(&req, clientInfo(c))
 	if err != nil {
-		response.Unauthorized(c, err.Error())
+		loginError(c, err)
+		return
+	}
+
+	if authResponse.TwoFactorRequired {
+		response.OK(c, "Two-factor authentication required", authResponse)
+		return
+	}
+
+	response.OK(c, "Login successful", authResponse)
+}
+
+// @Summary Complete two-factor login
+// @Description Exchange a login challenge token and a TOTP or recovery code for tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorLoginRequest true "Challenge token and code"
+// @Success 200 {object} response.Response{data=models.AuthResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/auth/login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	authResponse, err := h.twoFactorService.CompleteLogin(&req, clientInfo(c))
+	if err != nil {
+		loginError(c, err)
 		return
 	}
 
 	response.OK(c, "Login successful", authResponse)
 }
 
+// @Summary Start two-factor setup
+// @Description Generate a TOTP secret and otpauth URI for the authenticated user after confirming their password
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorSetupRequest true "Password"
+// @Success 200 {object} response.Response{data=models.TwoFactorSetupResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/2fa/setup [post]
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	var req models.TwoFactorSetupRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Two-factor setup started", setup)
+}
+
+// @Summary Enable two-factor authentication
+// @Description Confirm the TOTP secret with the password and a code, and receive recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorEnableRequest true "Password and TOTP code"
+// @Success 200 {object} response.Response{data=models.RecoveryCodesResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/2fa/enable [post]
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	var req models.TwoFactorEnableRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Two-factor authentication enabled", codes)
+}
+
+// @Summary Disable two-factor authentication
+// @Description Disable two-factor authentication using the password and a TOTP or recovery code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorDisableRequest true "Password and code"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	var req models.TwoFactorDisableRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.twoFactorService.Disable(userID, &req); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Two-factor authentication disabled", nil)
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes after confirming a TOTP code
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} response.Response{data=models.RecoveryCodesResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TwoFactorCodeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Recovery codes regenerated", codes)
+}
+
 // @Summary Refresh access token
 // @Description Get new access token using refresh token
 // @Tags auth
@@ -90,8 +281,12 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.RefreshToken(req.RefreshToken)
+	tokens, err := h.authService.RefreshToken(req.RefreshToken, clientInfo(c))
 	if err != nil {
+		if errors.Is(err, service.ErrRefreshFailed) {
+			response.InternalServerError(c, err.Error(), nil)
+			return
+		}
 		response.Unauthorized(c, err.Error())
 		return
 	}
@@ -100,7 +295,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 }
 
 // @Summary Logout user
-// @Description Invalidate refresh token
+// @Description Invalidate refresh token and revoke the current access token
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -119,7 +314,9 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.Logout(req.RefreshToken); err != nil {
+	claims, _ := middleware.GetTokenClaims(c)
+
+	if err := h.authService.Logout(req.RefreshToken, claims); err != nil {
 		response.BadRequest(c, "Failed to logout", err.Error())
 		return
 	}
@@ -127,10 +324,254 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	response.OK(c, "Logout successful", nil)
 }
 
+// @Summary List active sessions
+// @Description List the active sessions of the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.SessionResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	sessions, err := h.authService.GetSessions(userID)
+	if err != nil {
+		response.InternalServerError(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Sessions retrieved successfully", sessions)
+}
+
+// @Summary Revoke session
+// @Description Revoke one of the authenticated user's sessions
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	sessionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID", nil)
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.OK(c, "Session revoked successfully", nil)
+}
+
+// @Summary Logout everywhere
+// @Description Revoke all sessions of the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		response.InternalServerError(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Logged out from all sessions", nil)
+}
+
+// @Summary Login with Google
+// @Description Redirect to the Google consent screen using the authorization code flow with PKCE
+// @Tags auth
+// @Success 302 "Redirect to Google"
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/google [get]
 func (h *AuthHandler) GoogleLogin(c *gin.Context) {
-	response.OK(c, "Google OAuth not implemented yet", nil)
+	authURL, state, err := h.oauthService.GoogleAuthURL()
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	// Lax so the cookie survives the top-level redirect back from Google.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, int(service.OAuthStateTTL.Seconds()), oauthStateCookiePath, "", isSecureRequest(c), true)
+
+	c.Redirect(http.StatusFound, authURL)
 }
 
+// @Summary Google OAuth callback
+// @Description Exchange the authorization code for tokens and sign the user in
+// @Tags auth
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Success 200 {object} response.Response{data=models.AuthResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/auth/google/callback [get]
 func (h *AuthHandler) GoogleCallback(c *gin.Context) {
-	response.OK(c, "Google OAuth callback not implemented yet", nil)
+	if errParam := c.Query("error"); errParam != "" {
+		response.Unauthorized(c, "Google login was cancelled: "+errParam)
+		return
+	}
+
+	browserState, _ := c.Cookie(oauthStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, oauthStateCookiePath, "", isSecureRequest(c), true)
+
+	authResponse, err := h.oauthService.GoogleCallback(c.Query("code"), c.Query("state"), browserState, clientInfo(c))
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	if authResponse.TwoFactorRequired {
+		response.OK(c, "Two-factor authentication required", authResponse)
+		return
+	}
+
+	response.OK(c, "Login successful", authResponse)
+}
+
+// @Summary Verify email address
+// @Description Confirm ownership of the account email with the token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailVerificationRequest true "Verification token"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.EmailVerificationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	if err := h.verificationService.VerifyEmail(req.Token); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Email verified successfully", nil)
+}
+
+// @Summary Resend verification email
+// @Description Send a new verification email to the authenticated user
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.verificationService.SendEmailVerification(userID); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Verification email sent", nil)
+}
+
+// @Summary Request password reset
+// @Description Email a password reset link if the address belongs to an account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	if err := h.verificationService.RequestPasswordReset(req.Email); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "If the email is registered, a password reset link has been sent", nil)
+}
+
+// @Summary Reset password
+// @Description Set a new password with the token from the reset email and sign out all sessions
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	if err := h.verificationService.ResetPassword(&req); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Password reset successfully", nil)
+}
+
+func loginError(c *gin.Context, err error) {
+	var lockedErr *service.LoginLockedError
+	if errors.As(err, &lockedErr) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+		response.Error(c, http.StatusTooManyRequests, err.Error(), nil)
+		return
+	}
+
+	response.Unauthorized(c, err.Error())
+}
+
+func clientInfo(c *gin.Context) *models.ClientInfo {
+	return &models.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
+
+// isSecureRequest reports whether the client reached us over HTTPS, directly
+// or through a TLS-terminating proxy.
+func isSecureRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
 }
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"koda-shortlink-backend/internal/middleware"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/service"
+	"koda-shortlink-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FolderHandler struct {
+	folderService *service.FolderService
+}
+
+func NewFolderHandler(folderService *service.FolderService) *FolderHandler {
+	return &FolderHandler{folderService: folderService}
+}
+
+// @Summary Create folder
+// @Description Create a personal folder, or a folder in a workspace the user can edit, optionally inside another folder
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateFolderRequest true "Folder data"
+// @Success 201 {object} response.Response{data=models.FolderResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/folders [post]
+func (h *FolderHandler) CreateFolder(c *gin.Context) {
+	var req models.CreateFolderRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Folder created successfully", folder)
+}
+
+// @Summary List folders
+// @Description List the personal folders of the authenticated user, or the folders of a workspace they belong to, as a flat list linked by parent_id
+// @Tags folders
+// @Produce json
+// @Security BearerAuth
+// @Param workspace_id query int false "Workspace ID"
+// @Success 200 {object} response.Response{data=[]models.FolderResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/folders [get]
+func (h *FolderHandler) GetFolders(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	workspaceID, ok := workspaceIDQuery(c)
+	if !ok {
+		return
+	}
+
+	folders, err := h.folderService.GetFolders(userID, workspaceID)
+	if err != nil {
+		workspaceAccessError(c, err)
+		return
+	}
+
+	response.OK(c, "Folders retrieved successfully", folders)
+}
+
+// @Summary Update folder
+// @Description Rename a folder or move it under another folder (parent_id 0 moves it to the top level)
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Folder ID"
+// @Param request body models.UpdateFolderRequest true "Folder data"
+// @Success 200 {object} response.Response{data=models.FolderResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/folders/{id} [put]
+func (h *FolderHandler) UpdateFolder(c *gin.Context) {
+	var req models.UpdateFolderRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	folderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid folder ID", nil)
+		return
+	}
+
+	folder, err := h.folderService.UpdateFolder(folderID, userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Folder updated successfully", folder)
+}
+
+// @Summary Delete folder
+// @Description Delete a folder and its subfolders; the links inside are kept without a folder
+// @Tags folders
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Folder ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/folders/{id} [delete]
+func (h *FolderHandler) DeleteFolder(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	folderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid folder ID", nil)
+		return
+	}
+
+	if err := h.folderService.DeleteFolder(folderID, userID); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Folder deleted successfully", nil)
+}
@@ -1,15 +1,30 @@
 package handler
 
 import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
 	"koda-shortlink-backend/internal/middleware"
 	"koda-shortlink-backend/internal/models"
 	"koda-shortlink-backend/internal/service"
 	"koda-shortlink-backend/pkg/response"
+	"log"
+	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxBulkUploadBytes bounds the body of a bulk creation request, which
+// comfortably fits the 1000 rows the service accepts.
+const maxBulkUploadBytes = 5 << 20
+
+const maxLinkSearchLength = 200
+
 type LinkHandler struct {
 	linkService *service.LinkService
 }
@@ -50,15 +65,161 @@ func (h *LinkHandler) CreateLink(c *gin.Context) {
 	response.Created(c, "Link created successfully", link)
 }
 
+// @Summary Bulk create short links
+// @Description Create up to 1000 links at once from a JSON array, or from a CSV file with a header row naming the columns destination, custom_slug, title, description and expires_at (sent as the "file" field of a multipart form or as a text/csv body). Valid rows are created in a single transaction and every row gets its own result.
+// @Tags links
+// @Accept json,mpfd,text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param workspace_id query int false "Workspace ID"
+// @Param request body []models.BulkLinkItem false "Links to create"
+// @Param file formData file false "CSV file"
+// @Success 200 {object} response.Response{data=models.BulkCreateLinksResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/links/bulk [post]
+func (h *LinkHandler) BulkCreateLinks(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	workspaceID, ok := workspaceIDQuery(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkUploadBytes)
+
+	var items []models.BulkLinkItem
+	switch c.ContentType() {
+	case "multipart/form-data":
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			response.BadRequest(c, "CSV file is required", err.Error())
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			response.BadRequest(c, "Failed to read CSV file", err.Error())
+			return
+		}
+		defer file.Close()
+
+		items, err = parseBulkLinksCSV(file)
+		if err != nil {
+			response.BadRequest(c, "Invalid CSV file", err.Error())
+			return
+		}
+	case "text/csv":
+		var err error
+		items, err = parseBulkLinksCSV(c.Request.Body)
+		if err != nil {
+			response.BadRequest(c, "Invalid CSV file", err.Error())
+			return
+		}
+	default:
+		if err := c.ShouldBindJSON(&items); err != nil {
+			response.BadRequest(c, "Invalid input", err.Error())
+			return
+		}
+	}
+
+	result, err := h.linkService.BulkCreateLinks(items, userID, workspaceID)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Bulk link creation completed", result)
+}
+
+// @Summary Export links
+// @Description Stream the personal links of the authenticated user, or the links of a workspace they belong to, as CSV or NDJSON
+// @Tags exports
+// @Produce text/csv,application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "Export format: csv or ndjson" default(csv)
+// @Param workspace_id query int false "Workspace ID"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/exports/links [get]
+func (h *LinkHandler) ExportLinks(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	workspaceID, ok := workspaceIDQuery(c)
+	if !ok {
+		return
+	}
+
+	streamExport(c, "links", func(format string, w io.Writer) error {
+		return h.linkService.ExportLinks(userID, workspaceID, format, w)
+	})
+}
+
+// @Summary Export clicks
+// @Description Stream the raw click log of the personal links of the authenticated user, or of a workspace they belong to, as CSV or NDJSON
+// @Tags exports
+// @Produce text/csv,application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "Export format: csv or ndjson" default(csv)
+// @Param workspace_id query int false "Workspace ID"
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 30 days before end_date"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today"
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/exports/clicks [get]
+func (h *LinkHandler) ExportClicks(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	workspaceID, ok := workspaceIDQuery(c)
+	if !ok {
+		return
+	}
+
+	startDate := c.Query("start_date")
+	endDate := c.Query("end_date")
+
+	streamExport(c, "clicks", func(format string, w io.Writer) error {
+		return h.linkService.ExportClicks(userID, workspaceID, startDate, endDate, format, w)
+	})
+}
+
 // @Summary Get user links
-// @Description Get all links created by the authenticated user
+// @Description Get the personal links of the authenticated user, or the links of a workspace they belong to. Pages are numbered by default; pass pagination=cursor, then the returned next_cursor or prev_cursor as cursor, for keyset pagination without a total count. A cursor keeps the sort it was issued with.
 // @Tags links
 // @Produce json
 // @Security BearerAuth
+// @Param workspace_id query int false "Workspace ID"
+// @Param q query string false "Search in short code, destination, title and description"
+// @Param is_active query bool false "Filter by active status"
+// @Param expired query bool false "Filter by whether the link has expired"
+// @Param has_expiry query bool false "Filter by whether the link has an expiry date"
+// @Param folder_id query int false "Filter by folder"
+// @Param tag_id query []int false "Filter by tag, repeat to require several tags" collectionFormat(multi)
+// @Param sort query string false "Sort field: created_at, updated_at or clicks" default(created_at)
+// @Param order query string false "Sort order: asc or desc" default(desc)
+// @Param pagination query string false "Pagination mode: page or cursor" default(page)
+// @Param cursor query string false "Cursor from a previous response, implies pagination=cursor"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Items per page" default(10)
 // @Success 200 {object} response.Response{data=models.LinkListResponse}
+// @Success 200 {object} response.Response{data=models.LinkCursorListResponse}
+// @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Router /api/v1/links [get]
 func (h *LinkHandler) GetUserLinks(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
@@ -70,7 +231,55 @@ func (h *LinkHandler) GetUserLinks(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	links, err := h.linkService.GetUserLinks(userID, page, pageSize)
+	query, ok := linkListQuery(c)
+	if !ok {
+		return
+	}
+
+	workspaceID, ok := workspaceIDQuery(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("pagination") == "cursor" || c.Query("cursor") != "" {
+		cursor, ok := cursorQuery(c)
+		if !ok {
+			return
+		}
+
+		if workspaceID != nil {
+			links, err := h.linkService.GetWorkspaceLinksAfter(*workspaceID, userID, query, cursor, pageSize)
+			if err != nil {
+				workspaceAccessError(c, err)
+				return
+			}
+
+			response.OK(c, "Links retrieved successfully", links)
+			return
+		}
+
+		links, err := h.linkService.GetUserLinksAfter(userID, query, cursor, pageSize)
+		if err != nil {
+			response.InternalServerError(c, err.Error(), nil)
+			return
+		}
+
+		response.OK(c, "Links retrieved successfully", links)
+		return
+	}
+
+	if workspaceID != nil {
+		links, err := h.linkService.GetWorkspaceLinks(*workspaceID, userID, query, page, pageSize)
+		if err != nil {
+			workspaceAccessError(c, err)
+			return
+		}
+
+		response.OK(c, "Links retrieved successfully", links)
+		return
+	}
+
+	links, err := h.linkService.GetUserLinks(userID, query, page, pageSize)
 	if err != nil {
 		response.InternalServerError(c, err.Error(), nil)
 		return
@@ -106,6 +315,79 @@ func (h *LinkHandler) GetLinkByShortCode(c *gin.Context) {
 	response.OK(c, "Link retrieved successfully", link)
 }
 
+// @Summary Get link analytics
+// @Description Get click analytics of a specific link within a date range
+// @Tags links
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 30 days before end date"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} response.Response{data=models.ClickAnalytics}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/links/{shortCode}/analytics [get]
+func (h *LinkHandler) GetLinkAnalytics(c *gin.Context) {
+	shortCode := c.Param("shortCode")
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	analytics, err := h.linkService.GetLinkAnalytics(shortCode, userID, c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		var dateErr *service.DateRangeError
+		if errors.As(err, &dateErr) {
+			response.BadRequest(c, err.Error(), nil)
+			return
+		}
+		linkAccessError(c, err)
+		return
+	}
+
+	response.OK(c, "Analytics retrieved successfully", analytics)
+}
+
+// @Summary Get link clicks
+// @Description List the raw clicks of a link, newest first, using cursor pagination
+// @Tags links
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Param cursor query string false "Cursor from a previous response"
+// @Param page_size query int false "Items per page" default(10)
+// @Success 200 {object} response.Response{data=models.ClickListResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/links/{shortCode}/clicks [get]
+func (h *LinkHandler) GetLinkClicks(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	cursor, ok := cursorQuery(c)
+	if !ok {
+		return
+	}
+
+	clicks, err := h.linkService.GetLinkClicks(c.Param("shortCode"), userID, cursor, pageSize)
+	if err != nil {
+		linkAccessError(c, err)
+		return
+	}
+
+	response.OK(c, "Clicks retrieved successfully", clicks)
+}
+
 // @Summary Update link
 // @Description Update link details
 // @Tags links
@@ -169,12 +451,14 @@ func (h *LinkHandler) DeleteLink(c *gin.Context) {
 }
 
 // @Summary Get dashboard stats
-// @Description Get statistics for the authenticated user
+// @Description Get statistics for the personal links of the authenticated user, or for a workspace they belong to
 // @Tags dashboard
 // @Produce json
 // @Security BearerAuth
+// @Param workspace_id query int false "Workspace ID"
 // @Success 200 {object} response.Response{data=models.DashboardStats}
 // @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Router /api/v1/dashboard/stats [get]
 func (h *LinkHandler) GetDashboardStats(c *gin.Context) {
 	userID, exists := middleware.GetUserID(c)
@@ -183,6 +467,23 @@ func (h *LinkHandler) GetDashboardStats(c *gin.Context) {
 		return
 	}
 
+	if raw := c.Query("workspace_id"); raw != "" {
+		workspaceID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid workspace ID", nil)
+			return
+		}
+
+		stats, err := h.linkService.GetWorkspaceDashboardStats(workspaceID, userID)
+		if err != nil {
+			workspaceAccessError(c, err)
+			return
+		}
+
+		response.OK(c, "Statistics retrieved successfully", stats)
+		return
+	}
+
 	stats, err := h.linkService.GetDashboardStats(userID)
 	if err != nil {
 		response.InternalServerError(c, err.Error(), nil)
@@ -191,3 +492,226 @@ func (h *LinkHandler) GetDashboardStats(c *gin.Context) {
 
 	response.OK(c, "Statistics retrieved successfully", stats)
 }
+
+// streamExport writes an export straight to the response as an attachment.
+// Errors raised before the first byte, such as invalid dates or a missing
+// workspace role, become a regular error response; later ones can only be
+// logged since the status has already been sent.
+func streamExport(c *gin.Context, name string, export func(format string, w io.Writer) error) {
+	format := c.DefaultQuery("format", service.ExportFormatCSV)
+
+	var contentType string
+	switch format {
+	case service.ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case service.ExportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	default:
+		response.BadRequest(c, "Invalid format, expected csv or ndjson", nil)
+		return
+	}
+
+	filename := fmt.Sprintf("koda-%s-%s.%s", name, time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if err := export(format, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			response.BadRequest(c, err.Error(), nil)
+			return
+		}
+		log.Printf("Failed to export %s: %v", name, err)
+	}
+}
+
+// linkListQuery reads the search, filter and sort parameters of a link list
+// request, answering with 400 when one is malformed.
+func linkListQuery(c *gin.Context) (*models.LinkListQuery, bool) {
+	query := &models.LinkListQuery{
+		Search:    c.Query("q"),
+		SortBy:    c.Query("sort"),
+		SortOrder: strings.ToLower(c.Query("order")),
+	}
+
+	if len(query.Search) > maxLinkSearchLength {
+		response.BadRequest(c, fmt.Sprintf("Search query must be at most %d characters", maxLinkSearchLength), nil)
+		return nil, false
+	}
+
+	switch query.SortBy {
+	case "", models.LinkSortCreatedAt, models.LinkSortUpdatedAt, models.LinkSortClicks:
+	default:
+		response.BadRequest(c, "Invalid sort field, expected created_at, updated_at or clicks", nil)
+		return nil, false
+	}
+
+	switch query.SortOrder {
+	case "", models.SortOrderAsc, models.SortOrderDesc:
+	default:
+		response.BadRequest(c, "Invalid sort order, expected asc or desc", nil)
+		return nil, false
+	}
+
+	filters := []struct {
+		key   string
+		value **bool
+	}{
+		{"is_active", &query.IsActive},
+		{"expired", &query.Expired},
+		{"has_expiry", &query.HasExpiry},
+	}
+	for _, filter := range filters {
+		value, err := optionalBoolQuery(c, filter.key)
+		if err != nil {
+			response.BadRequest(c, fmt.Sprintf("Invalid %s value", filter.key), nil)
+			return nil, false
+		}
+		*filter.value = value
+	}
+
+	if raw := c.Query("folder_id"); raw != "" {
+		folderID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid folder_id value", nil)
+			return nil, false
+		}
+		query.FolderID = &folderID
+	}
+
+	for _, raw := range c.QueryArray("tag_id") {
+		tagID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid tag_id value", nil)
+			return nil, false
+		}
+		if !slices.Contains(query.TagIDs, tagID) {
+			query.TagIDs = append(query.TagIDs, tagID)
+		}
+	}
+
+	return query, true
+}
+
+func cursorQuery(c *gin.Context) (*models.Cursor, bool) {
+	raw := c.Query("cursor")
+	if raw == "" {
+		return nil, true
+	}
+
+	cursor, err := models.DecodeCursor(raw)
+	if err != nil {
+		response.BadRequest(c, "Invalid cursor", nil)
+		return nil, false
+	}
+
+	return cursor, true
+}
+
+func workspaceIDQuery(c *gin.Context) (*int64, bool) {
+	raw := c.Query("workspace_id")
+	if raw == "" {
+		return nil, true
+	}
+
+	workspaceID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid workspace ID", nil)
+		return nil, false
+	}
+
+	return &workspaceID, true
+}
+
+// workspaceAccessError answers 403 when the user lacks access to the
+// workspace and 500 for any other failure, such as the database being down.
+func workspaceAccessError(c *gin.Context, err error) {
+	var permissionErr *service.PermissionError
+	if errors.As(err, &permissionErr) {
+		response.Forbidden(c, err.Error())
+		return
+	}
+
+	response.InternalServerError(c, err.Error(), nil)
+}
+
+// linkAccessError answers 404 for a missing link and otherwise defers to
+// workspaceAccessError.
+func linkAccessError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrLinkNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	workspaceAccessError(c, err)
+}
+
+// parseBulkLinksCSV reads bulk link rows from CSV. The header row maps
+// columns by name, in any order; unknown columns are ignored and empty
+// cells leave the optional fields unset.
+func parseBulkLinksCSV(r io.Reader) ([]models.BulkLinkItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			// Spreadsheet exports often start with a UTF-8 byte order mark.
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["destination"]; !ok {
+		return nil, errors.New("missing destination column")
+	}
+
+	cell := func(record []string, column string) *string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return nil
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+
+	var items []models.BulkLinkItem
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		item := models.BulkLinkItem{
+			CustomSlug:  cell(record, "custom_slug"),
+			Title:       cell(record, "title"),
+			Description: cell(record, "description"),
+			ExpiresAt:   cell(record, "expires_at"),
+		}
+		if destination := cell(record, "destination"); destination != nil {
+			item.Destination = *destination
+		}
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("file has no rows")
+	}
+
+	return items, nil
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"koda-shortlink-backend/internal/geoip"
 	"koda-shortlink-backend/internal/models"
 	"koda-shortlink-backend/internal/service"
 	"koda-shortlink-backend/internal/utils"
@@ -12,14 +13,16 @@ import (
 )
 
 type RedirectHandler struct {
-	linkService *service.LinkService
-	redisClient *redis.Client
+	linkService   *service.LinkService
+	redisClient   *redis.Client
+	geoipResolver geoip.Resolver
 }
 
-func NewRedirectHandler(linkService *service.LinkService, redisClient *redis.Client) *RedirectHandler {
+func NewRedirectHandler(linkService *service.LinkService, redisClient *redis.Client, geoipResolver geoip.Resolver) *RedirectHandler {
 	return &RedirectHandler{
-		linkService: linkService,
-		redisClient: redisClient,
+		linkService:   linkService,
+		redisClient:   redisClient,
+		geoipResolver: geoipResolver,
 	}
 }
 
@@ -65,6 +68,15 @@ func (h *RedirectHandler) Redirect(c *gin.Context) {
 			}
 		}()
 
+		if location, err := h.geoipResolver.Lookup(click.IPAddress); err == nil {
+			if location.Country != "" {
+				click.Country = &location.Country
+			}
+			if location.City != "" {
+				click.City = &location.City
+			}
+		}
+
 		if err := h.linkService.RecordClick(shortCode, click); err != nil {
 			log.Printf("Failed to record click for %s: %v", shortCode, err)
 		}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"koda-shortlink-backend/internal/middleware"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/service"
+	"koda-shortlink-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	tagService *service.TagService
+}
+
+func NewTagHandler(tagService *service.TagService) *TagHandler {
+	return &TagHandler{tagService: tagService}
+}
+
+// @Summary Create tag
+// @Description Create a personal tag, or a tag in a workspace the user can edit
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateTagRequest true "Tag data"
+// @Success 201 {object} response.Response{data=models.TagResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/tags [post]
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var req models.CreateTagRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Tag created successfully", tag)
+}
+
+// @Summary List tags
+// @Description List the personal tags of the authenticated user, or the tags of a workspace they belong to
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param workspace_id query int false "Workspace ID"
+// @Success 200 {object} response.Response{data=[]models.TagResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/tags [get]
+func (h *TagHandler) GetTags(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	workspaceID, ok := workspaceIDQuery(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.tagService.GetTags(userID, workspaceID)
+	if err != nil {
+		workspaceAccessError(c, err)
+		return
+	}
+
+	response.OK(c, "Tags retrieved successfully", tags)
+}
+
+// @Summary Update tag
+// @Description Rename or recolor a tag
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Param request body models.UpdateTagRequest true "Tag data"
+// @Success 200 {object} response.Response{data=models.TagResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/tags/{id} [put]
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	var req models.UpdateTagRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	tagID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid tag ID", nil)
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(tagID, userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Tag updated successfully", tag)
+}
+
+// @Summary Delete tag
+// @Description Delete a tag and remove it from all links
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tag ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	tagID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid tag ID", nil)
+		return
+	}
+
+	if err := h.tagService.DeleteTag(tagID, userID); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Tag deleted successfully", nil)
+}
+
+// @Summary Set link tags
+// @Description Replace the tags of a link. Tags must have the same owner as the link; an empty list removes all tags.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shortCode path string true "Short code"
+// @Param request body models.SetLinkTagsRequest true "Tag IDs"
+// @Success 200 {object} response.Response{data=[]models.TagResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/links/{shortCode}/tags [put]
+func (h *TagHandler) SetLinkTags(c *gin.Context) {
+	var req models.SetLinkTagsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	tags, err := h.tagService.SetLinkTags(c.Param("shortCode"), userID, req.TagIDs)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Link tags updated successfully", tags)
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"fmt"
+	"koda-shortlink-backend/internal/middleware"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/service"
+	"koda-shortlink-backend/pkg/response"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	userService    *service.UserService
+	accountService *service.AccountService
+}
+
+func NewUserHandler(userService *service.UserService, accountService *service.AccountService) *UserHandler {
+	return &UserHandler{
+		userService:    userService,
+		accountService: accountService,
+	}
+}
+
+// @Summary Get profile
+// @Description Get the profile of the authenticated user
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/users/me [get]
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	profile, err := h.userService.GetProfile(userID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.OK(c, "Profile retrieved successfully", profile)
+}
+
+// @Summary Update profile
+// @Description Update the profile of the authenticated user
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdateProfileRequest true "Profile data"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/users/me [put]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var req models.UpdateProfileRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.userService.UpdateProfile(userID, &req); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	profile, err := h.userService.GetProfile(userID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.OK(c, "Profile updated successfully", profile)
+}
+
+// @Summary Change password
+// @Description Change the password of the authenticated user, revoke all other sessions and all issued access tokens
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.ChangePasswordRequest true "Password data"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/users/me/password [post]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.userService.ChangePassword(userID, &req); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Password changed successfully", nil)
+}
+
+// @Summary Delete account
+// @Description Soft-delete the authenticated user, revoke all sessions and disable their links. The account is permanently purged after the grace period.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.DeleteAccountRequest true "Password or emailed confirmation token"
+// @Success 200 {object} response.Response{data=models.DeleteAccountResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/users/me [delete]
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	var req models.DeleteAccountRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	result, err := h.accountService.DeleteAccount(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Account deleted successfully", result)
+}
+
+// @Summary Request account deletion confirmation
+// @Description Email a single-use token that confirms account deletion in place of the password, for accounts created through Google
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/users/me/delete-confirmation [post]
+func (h *UserHandler) RequestDeletionConfirmation(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	if err := h.accountService.RequestDeletionConfirmation(userID); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Confirmation email sent", nil)
+}
+
+// @Summary Export account data
+// @Description Download the profile, links and click history of the authenticated user as a JSON document or ZIP archive
+// @Tags users
+// @Produce json,application/zip
+// @Security BearerAuth
+// @Param format query string false "Export format: json or zip" default(json)
+// @Success 200 {file} file
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/users/me/export [get]
+func (h *UserHandler) ExportData(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	format := c.DefaultQuery("format", service.ExportFormatJSON)
+
+	var contentType string
+	switch format {
+	case service.ExportFormatJSON:
+		contentType = "application/json"
+	case service.ExportFormatZIP:
+		contentType = "application/zip"
+	default:
+		response.BadRequest(c, "Invalid format, expected json or zip", nil)
+		return
+	}
+
+	filename := fmt.Sprintf("koda-export-%d-%s.%s", userID, time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	if err := h.accountService.ExportData(userID, format, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			response.InternalServerError(c, "Failed to export data", err.Error())
+			return
+		}
+		log.Printf("Failed to export data for user %d: %v", userID, err)
+	}
+}
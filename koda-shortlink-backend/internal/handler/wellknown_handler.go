@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"koda-shortlink-backend/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WellKnownHandler struct {
+	jwtUtil *utils.JWTUtil
+}
+
+func NewWellKnownHandler(jwtUtil *utils.JWTUtil) *WellKnownHandler {
+	return &WellKnownHandler{jwtUtil: jwtUtil}
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys used to verify tokens issued by this service
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.jwtUtil.JWKS())
+}
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"koda-shortlink-backend/internal/middleware"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/service"
+	"koda-shortlink-backend/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceHandler struct {
+	workspaceService *service.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService *service.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
+}
+
+// @Summary Create workspace
+// @Description Create a workspace owned by the authenticated user
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateWorkspaceRequest true "Workspace data"
+// @Success 201 {object} response.Response{data=models.WorkspaceResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/workspaces [post]
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req models.CreateWorkspaceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Workspace created successfully", workspace)
+}
+
+// @Summary List workspaces
+// @Description List the workspaces the authenticated user belongs to
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]models.WorkspaceResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/workspaces [get]
+func (h *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return
+	}
+
+	workspaces, err := h.workspaceService.GetWorkspaces(userID)
+	if err != nil {
+		response.InternalServerError(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Workspaces retrieved successfully", workspaces)
+}
+
+// @Summary Get workspace
+// @Description Get a workspace the authenticated user belongs to
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Success 200 {object} response.Response{data=models.WorkspaceResponse}
+// @Failure 403 {object} response.Response
+// @Router /api/v1/workspaces/{id} [get]
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	userID, workspaceID, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(workspaceID, userID)
+	if err != nil {
+		response.Forbidden(c, err.Error())
+		return
+	}
+
+	response.OK(c, "Workspace retrieved successfully", workspace)
+}
+
+// @Summary Update workspace
+// @Description Rename a workspace (owners only)
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Param request body models.UpdateWorkspaceRequest true "Workspace data"
+// @Success 200 {object} response.Response{data=models.WorkspaceResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/workspaces/{id} [put]
+func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
+	var req models.UpdateWorkspaceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, workspaceID, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	workspace, err := h.workspaceService.UpdateWorkspace(workspaceID, userID, &req)
+	if err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Workspace updated successfully", workspace)
+}
+
+// @Summary Delete workspace
+// @Description Delete a workspace and all of its links (owners only)
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/workspaces/{id} [delete]
+func (h *WorkspaceHandler) DeleteWorkspace(c *gin.Context) {
+	userID, workspaceID, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.DeleteWorkspace(workspaceID, userID); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Workspace deleted successfully", nil)
+}
+
+// @Summary List workspace members
+// @Description List the members of a workspace and their roles
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Success 200 {object} response.Response{data=[]models.WorkspaceMemberResponse}
+// @Failure 403 {object} response.Response
+// @Router /api/v1/workspaces/{id}/members [get]
+func (h *WorkspaceHandler) GetMembers(c *gin.Context) {
+	userID, workspaceID, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.workspaceService.GetMembers(workspaceID, userID)
+	if err != nil {
+		response.Forbidden(c, err.Error())
+		return
+	}
+
+	response.OK(c, "Members retrieved successfully", members)
+}
+
+// @Summary Add workspace member
+// @Description Add a registered user to a workspace as owner, editor or viewer (owners only)
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Param request body models.AddWorkspaceMemberRequest true "Member data"
+// @Success 201 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/workspaces/{id}/members [post]
+func (h *WorkspaceHandler) AddMember(c *gin.Context) {
+	var req models.AddWorkspaceMemberRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, workspaceID, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.workspaceService.AddMember(workspaceID, userID, &req); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.Created(c, "Member added successfully", nil)
+}
+
+// @Summary Update workspace member
+// @Description Change the role of a workspace member (owners only)
+// @Tags workspaces
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Param userId path int true "Member user ID"
+// @Param request body models.UpdateWorkspaceMemberRequest true "Role"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/workspaces/{id}/members/{userId} [put]
+func (h *WorkspaceHandler) UpdateMember(c *gin.Context) {
+	var req models.UpdateWorkspaceMemberRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid input", err.Error())
+		return
+	}
+
+	userID, workspaceID, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.workspaceService.UpdateMemberRole(workspaceID, userID, memberID, &req); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Member updated successfully", nil)
+}
+
+// @Summary Remove workspace member
+// @Description Remove a member from a workspace (owners only), or leave it by passing your own user ID
+// @Tags workspaces
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Workspace ID"
+// @Param userId path int true "Member user ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/workspaces/{id}/members/{userId} [delete]
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	userID, workspaceID, ok := workspaceParams(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(workspaceID, userID, memberID); err != nil {
+		response.BadRequest(c, err.Error(), nil)
+		return
+	}
+
+	response.OK(c, "Member removed successfully", nil)
+}
+
+func workspaceParams(c *gin.Context) (int64, int64, bool) {
+	userID, exists := middleware.GetUserID(c)
+	if !exists {
+		response.Unauthorized(c, "Unauthorized")
+		return 0, 0, false
+	}
+
+	workspaceID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid workspace ID", nil)
+		return 0, 0, false
+	}
+
+	return userID, workspaceID, true
+}
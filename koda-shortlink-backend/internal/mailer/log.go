@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type LogMailer struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func NewLogMailer(filePath string) *LogMailer {
+	return &LogMailer{filePath: filePath}
+}
+
+func (m *LogMailer) Send(msg *Message) error {
+	entry := fmt.Sprintf("=== %s ===\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+
+	if m.filePath == "" {
+		log.Printf("[MAIL] %s", entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening mail log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"koda-shortlink-backend/internal/models"
 	"koda-shortlink-backend/internal/utils"
 	"koda-shortlink-backend/pkg/response"
 	"strings"
@@ -8,17 +9,40 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// APIKeyAuthenticator resolves a raw "ApiKey" credential to an active key.
+type APIKeyAuthenticator interface {
+	Authenticate(rawKey string) (*models.APIKey, error)
+}
+
+// RoleProvider returns the current role of an active user.
+type RoleProvider interface {
+	GetRole(userID int64) (string, error)
+}
+
+// RevocationChecker reports whether a validated token has been revoked.
+type RevocationChecker interface {
+	IsRevoked(claims *utils.JWTClaims) (bool, error)
+}
+
 type AuthMiddleware struct {
-	jwtUtil *utils.JWTUtil
+	jwtUtil  *utils.JWTUtil
+	denylist RevocationChecker
+	apiKeys  APIKeyAuthenticator
+	roles    RoleProvider
 }
 
-func NewAuthMiddleware(jwtUtil *utils.JWTUtil) *AuthMiddleware {
+func NewAuthMiddleware(jwtUtil *utils.JWTUtil, denylist RevocationChecker, apiKeys APIKeyAuthenticator, roles RoleProvider) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtUtil: jwtUtil,
+		jwtUtil:  jwtUtil,
+		denylist: denylist,
+		apiKeys:  apiKeys,
+		roles:    roles,
 	}
 }
 
-func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
+// RequireAuth accepts a Bearer access token. When scopes are given, an
+// "ApiKey" credential holding all of them is accepted as well.
+func (m *AuthMiddleware) RequireAuth(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -28,6 +52,11 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "ApiKey" && len(scopes) > 0 {
+			m.authenticateAPIKey(c, parts[1], scopes)
+			return
+		}
+
 		if len(parts) != 2 || parts[0] != "Bearer" {
 			response.Unauthorized(c, "Invalid authorization header format")
 			c.Abort()
@@ -35,31 +64,84 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		}
 
 		token := parts[1]
-		claims, err := m.jwtUtil.ValidateToken(token)
+		claims, err := m.jwtUtil.ValidateToken(token, utils.TokenTypeAccess)
 		if err != nil {
 			response.Unauthorized(c, "Invalid or expired token")
 			c.Abort()
 			return
 		}
 
+		revoked, err := m.denylist.IsRevoked(claims)
+		if err != nil || revoked {
+			response.Unauthorized(c, "Token has been revoked")
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
+		c.Set("token_claims", claims)
 
 		c.Next()
 	}
 }
 
-func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
+// RequireRole must run after RequireAuth. The role is loaded from the
+// database on every request, and API keys are never granted a role.
+func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserID(c)
+		if !exists {
+			response.Unauthorized(c, "Unauthorized")
+			c.Abort()
+			return
+		}
+
+		if _, isAPIKey := GetAPIKey(c); isAPIKey {
+			response.Forbidden(c, "API keys cannot access this resource")
+			c.Abort()
+			return
+		}
+
+		role, err := m.roles.GetRole(userID)
+		if err != nil {
+			response.Unauthorized(c, err.Error())
+			c.Abort()
+			return
+		}
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Set("user_role", role)
+				c.Next()
+				return
+			}
+		}
+
+		response.Forbidden(c, "Insufficient permissions")
+		c.Abort()
+	}
+}
+
+func (m *AuthMiddleware) OptionalAuth(scopes ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
 			parts := strings.Split(authHeader, " ")
+			if len(parts) == 2 && parts[0] == "ApiKey" && len(scopes) > 0 {
+				m.authenticateAPIKey(c, parts[1], scopes)
+				return
+			}
+
 			if len(parts) == 2 && parts[0] == "Bearer" {
 				token := parts[1]
-				claims, err := m.jwtUtil.ValidateToken(token)
+				claims, err := m.jwtUtil.ValidateToken(token, utils.TokenTypeAccess)
 				if err == nil {
-					c.Set("user_id", claims.UserID)
-					c.Set("user_email", claims.Email)
+					if revoked, err := m.denylist.IsRevoked(claims); err == nil && !revoked {
+						c.Set("user_id", claims.UserID)
+						c.Set("user_email", claims.Email)
+						c.Set("token_claims", claims)
+					}
 				}
 			}
 		}
@@ -68,6 +150,29 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 	}
 }
 
+func (m *AuthMiddleware) authenticateAPIKey(c *gin.Context, rawKey string, scopes []string) {
+	apiKey, err := m.apiKeys.Authenticate(rawKey)
+	if err != nil {
+		response.Unauthorized(c, "Invalid or expired API key")
+		c.Abort()
+		return
+	}
+
+	for _, scope := range scopes {
+		if !apiKey.HasScope(scope) {
+			response.Forbidden(c, "API key is missing the "+scope+" scope")
+			c.Abort()
+			return
+		}
+	}
+
+	c.Set("user_id", apiKey.UserID)
+	c.Set("user_email", apiKey.UserEmail)
+	c.Set("api_key", apiKey)
+
+	c.Next()
+}
+
 func GetUserID(c *gin.Context) (int64, bool) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -77,3 +182,23 @@ func GetUserID(c *gin.Context) (int64, bool) {
 	id, ok := userID.(int64)
 	return id, ok
 }
+
+func GetTokenClaims(c *gin.Context) (*utils.JWTClaims, bool) {
+	claims, exists := c.Get("token_claims")
+	if !exists {
+		return nil, false
+	}
+
+	tokenClaims, ok := claims.(*utils.JWTClaims)
+	return tokenClaims, ok
+}
+
+func GetAPIKey(c *gin.Context) (*models.APIKey, bool) {
+	apiKey, exists := c.Get("api_key")
+	if !exists {
+		return nil, false
+	}
+
+	key, ok := apiKey.(*models.APIKey)
+	return key, ok
+}
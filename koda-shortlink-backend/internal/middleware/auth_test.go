@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"errors"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeRevocations struct {
+	revoked map[string]bool
+}
+
+func (f *fakeRevocations) IsRevoked(claims *utils.JWTClaims) (bool, error) {
+	return f.revoked[claims.ID], nil
+}
+
+type fakeAPIKeys struct{}
+
+func (fakeAPIKeys) Authenticate(rawKey string) (*models.APIKey, error) {
+	if rawKey != "ksk_valid" {
+		return nil, errors.New("invalid api key")
+	}
+	return &models.APIKey{ID: 1, UserID: 1, Scopes: []string{models.ScopeLinksRead}}, nil
+}
+
+type fakeRoles struct{}
+
+func (fakeRoles) GetRole(userID int64) (string, error) {
+	return models.RoleUser, nil
+}
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func newTestMiddleware() (*AuthMiddleware, *utils.JWTUtil, *fakeRevocations) {
+	jwtUtil := utils.NewJWTUtil("test-secret", 15*time.Minute, 168*time.Hour)
+	revocations := &fakeRevocations{revoked: map[string]bool{}}
+	return NewAuthMiddleware(jwtUtil, revocations, fakeAPIKeys{}, fakeRoles{}), jwtUtil, revocations
+}
+
+// serve runs a request through the middleware and reports the status code
+// and the user ID seen by the handler, or 0 when it was not reached or the
+// request was anonymous.
+func serve(t *testing.T, handler gin.HandlerFunc, authorization string) (int, int64) {
+	t.Helper()
+
+	var seenUserID int64
+	router := gin.New()
+	router.GET("/protected", handler, func(c *gin.Context) {
+		seenUserID, _ = GetUserID(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec.Code, seenUserID
+}
+
+func generateToken(t *testing.T, jwtUtil *utils.JWTUtil, tokenType string) (string, *utils.JWTClaims) {
+	t.Helper()
+
+	token, err := jwtUtil.GenerateToken(42, "user@example.com", tokenType, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken(%s): %v", tokenType, err)
+	}
+
+	claims, err := jwtUtil.ValidateToken(token, tokenType)
+	if err != nil {
+		t.Fatalf("ValidateToken(%s): %v", tokenType, err)
+	}
+
+	return token, claims
+}
+
+func TestRequireAuthAcceptsAccessToken(t *testing.T) {
+	m, jwtUtil, _ := newTestMiddleware()
+	token, _ := generateToken(t, jwtUtil, utils.TokenTypeAccess)
+
+	status, userID := serve(t, m.RequireAuth(), "Bearer "+token)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+	if userID != 42 {
+		t.Fatalf("user_id = %d, want 42", userID)
+	}
+}
+
+func TestRequireAuthRejectsNonAccessTokens(t *testing.T) {
+	m, jwtUtil, _ := newTestMiddleware()
+
+	tokenTypes := []string{
+		utils.TokenTypeRefresh,
+		utils.TokenTypeChallenge,
+		utils.TokenTypeEmailVerification,
+		utils.TokenTypePasswordReset,
+		utils.TokenTypeAccountDeletion,
+	}
+
+	for _, tokenType := range tokenTypes {
+		t.Run(tokenType, func(t *testing.T) {
+			token, _ := generateToken(t, jwtUtil, tokenType)
+
+			status, userID := serve(t, m.RequireAuth(), "Bearer "+token)
+			if status != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want %d", status, http.StatusUnauthorized)
+			}
+			if userID != 0 {
+				t.Fatalf("handler reached with user_id %d", userID)
+			}
+
+			status, _ = serve(t, m.RequireAuth(models.ScopeLinksRead), "Bearer "+token)
+			if status != http.StatusUnauthorized {
+				t.Fatalf("status with scopes = %d, want %d", status, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestRequireAuthRejectsRevokedToken(t *testing.T) {
+	m, jwtUtil, revocations := newTestMiddleware()
+	token, claims := generateToken(t, jwtUtil, utils.TokenTypeAccess)
+	revocations.revoked[claims.ID] = true
+
+	status, _ := serve(t, m.RequireAuth(), "Bearer "+token)
+	if status != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireAuthRejectsMalformedHeaders(t *testing.T) {
+	m, _, _ := newTestMiddleware()
+
+	headers := []string{"", "Bearer", "Token abc", "Bearer not-a-jwt", "ApiKey ksk_valid"}
+
+	for _, header := range headers {
+		status, _ := serve(t, m.RequireAuth(), header)
+		if status != http.StatusUnauthorized {
+			t.Errorf("header %q: status = %d, want %d", header, status, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestRequireAuthAPIKeyScopes(t *testing.T) {
+	m, _, _ := newTestMiddleware()
+
+	tests := []struct {
+		name   string
+		scopes []string
+		header string
+		status int
+	}{
+		{"key with scope", []string{models.ScopeLinksRead}, "ApiKey ksk_valid", http.StatusOK},
+		{"key missing scope", []string{models.ScopeLinksWrite}, "ApiKey ksk_valid", http.StatusForbidden},
+		{"unknown key", []string{models.ScopeLinksRead}, "ApiKey ksk_unknown", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := serve(t, m.RequireAuth(tt.scopes...), tt.header)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
+
+func TestOptionalAuthIgnoresRefreshToken(t *testing.T) {
+	m, jwtUtil, _ := newTestMiddleware()
+	refreshToken, _ := generateToken(t, jwtUtil, utils.TokenTypeRefresh)
+	accessToken, _ := generateToken(t, jwtUtil, utils.TokenTypeAccess)
+
+	status, userID := serve(t, m.OptionalAuth(), "Bearer "+refreshToken)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d", status, http.StatusOK)
+	}
+	if userID != 0 {
+		t.Fatalf("refresh token authenticated user %d", userID)
+	}
+
+	_, userID = serve(t, m.OptionalAuth(), "Bearer "+accessToken)
+	if userID != 42 {
+		t.Fatalf("access token: user_id = %d, want 42", userID)
+	}
+}
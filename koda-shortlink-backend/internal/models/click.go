@@ -18,21 +18,44 @@ type Click struct {
 	ClickedAt  time.Time `json:"clicked_at" db:"clicked_at"`
 }
 
+type ClickListResponse struct {
+	Clicks     []Click `json:"clicks"`
+	PageSize   int     `json:"page_size"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	PrevCursor string  `json:"prev_cursor,omitempty"`
+}
+
+type ClickRecord struct {
+	ShortCode string `json:"short_code"`
+	Click
+}
+
+// ClickVisitor is the part of a click that identifies its visitor, along
+// with the owner of the clicked link.
+type ClickVisitor struct {
+	UserID      *int64
+	WorkspaceID *int64
+	IPAddress   string
+	UserAgent   string
+	ClickedAt   time.Time
+}
+
 type ClickAnalytics struct {
-	TotalClicks   int64                  `json:"total_clicks"`
-	UniqueClicks  int64                  `json:"unique_clicks"`
-	ClicksByDay   []ClicksByDay          `json:"clicks_by_day"`
-	TopCountries  []CountryStats         `json:"top_countries"`
-	TopCities     []CityStats            `json:"top_cities"`
-	TopReferers   []RefererStats         `json:"top_referers"`
-	DeviceStats   []DeviceStats          `json:"device_stats"`
-	BrowserStats  []BrowserStats         `json:"browser_stats"`
-	OSStats       []OSStats              `json:"os_stats"`
+	TotalClicks  int64          `json:"total_clicks"`
+	UniqueClicks int64          `json:"unique_clicks"`
+	ClicksByDay  []ClicksByDay  `json:"clicks_by_day"`
+	TopCountries []CountryStats `json:"top_countries"`
+	TopCities    []CityStats    `json:"top_cities"`
+	TopReferers  []RefererStats `json:"top_referers"`
+	DeviceStats  []DeviceStats  `json:"device_stats"`
+	BrowserStats []BrowserStats `json:"browser_stats"`
+	OSStats      []OSStats      `json:"os_stats"`
 }
 
 type ClicksByDay struct {
-	Date  string `json:"date"`
-	Count int64  `json:"count"`
+	Date        string `json:"date"`
+	Count       int64  `json:"count"`
+	UniqueCount int64  `json:"unique_count"`
 }
 
 type CountryStats struct {
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// CursorTimeLayout formats timestamp sort keys the way Postgres prints a
+// TIMESTAMP column, so they round-trip without time zone conversion.
+const CursorTimeLayout = "2006-01-02 15:04:05.999999"
+
+// Cursor marks a row in a keyset-paginated listing: the value of the sort
+// column and the ID that breaks ties. Backward cursors page towards the
+// start of the listing. Clients only ever see the encoded form.
+type Cursor struct {
+	Value    string `json:"v"`
+	ID       int64  `json:"id"`
+	Sort     string `json:"s,omitempty"`
+	Order    string `json:"o,omitempty"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor also checks that Value parses as the type of its sort field,
+// since it ends up cast in SQL. Cursors without a Sort, such as those of click
+// listings, hold a timestamp.
+func DecodeCursor(encoded string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	cursor := &Cursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID <= 0 {
+		return nil, errors.New("invalid cursor")
+	}
+
+	switch cursor.Sort {
+	case "", LinkSortCreatedAt, LinkSortUpdatedAt:
+		_, err = time.Parse(CursorTimeLayout, cursor.Value)
+	case LinkSortClicks:
+		_, err = strconv.ParseInt(cursor.Value, 10, 64)
+	default:
+		err = errors.New("unknown sort field")
+	}
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	switch cursor.Order {
+	case "", SortOrderAsc, SortOrderDesc:
+	default:
+		return nil, errors.New("invalid cursor")
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// Folder groups links in a hierarchy. Ownership works as for tags, and a
+// folder's parent always has the same owner.
+type Folder struct {
+	ID          int64     `json:"id" db:"id"`
+	UserID      *int64    `json:"user_id,omitempty" db:"user_id"`
+	WorkspaceID *int64    `json:"workspace_id,omitempty" db:"workspace_id"`
+	ParentID    *int64    `json:"parent_id,omitempty" db:"parent_id"`
+	Name        string    `json:"name" db:"name"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateFolderRequest struct {
+	Name        string `json:"name" binding:"required,min=1,max=100"`
+	ParentID    *int64 `json:"parent_id,omitempty"`
+	WorkspaceID *int64 `json:"workspace_id,omitempty"`
+}
+
+// UpdateFolderRequest renames and/or moves a folder. A parent_id of 0 moves
+// it to the top level.
+type UpdateFolderRequest struct {
+	Name     *string `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	ParentID *int64  `json:"parent_id,omitempty"`
+}
+
+type FolderResponse struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	ParentID    *int64    `json:"parent_id,omitempty"`
+	WorkspaceID *int64    `json:"workspace_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (f *Folder) ToResponse() *FolderResponse {
+	return &FolderResponse{
+		ID:          f.ID,
+		Name:        f.Name,
+		ParentID:    f.ParentID,
+		WorkspaceID: f.WorkspaceID,
+		CreatedAt:   f.CreatedAt,
+	}
+}
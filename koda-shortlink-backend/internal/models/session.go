@@ -5,13 +5,31 @@ import (
 )
 
 type Session struct {
-	ID           int64     `json:"id" db:"id"`
-	UserID       int64     `json:"user_id" db:"user_id"`
-	RefreshToken string    `json:"refresh_token" db:"refresh_token"`
-	UserAgent    string    `json:"user_agent" db:"user_agent"`
-	IPAddress    string    `json:"ip_address" db:"ip_address"`
-	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ID               int64      `json:"id" db:"id"`
+	UserID           int64      `json:"user_id" db:"user_id"`
+	RefreshTokenHash string     `json:"-" db:"refresh_token_hash"`
+	FamilyID         string     `json:"family_id" db:"family_id"`
+	UserAgent        string     `json:"user_agent" db:"user_agent"`
+	IPAddress        string     `json:"ip_address" db:"ip_address"`
+	ExpiresAt        time.Time  `json:"expires_at" db:"expires_at"`
+	RotatedAt        *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+type SessionResponse struct {
+	ID         int64     `json:"id"`
+	DeviceType string    `json:"device_type"`
+	Browser    string    `json:"browser"`
+	OS         string    `json:"os"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
 }
 
 type OAuthAccount struct {
@@ -29,6 +47,8 @@ type TokenPair struct {
 }
 
 type AuthResponse struct {
-	User   *UserResponse `json:"user"`
-	Tokens *TokenPair    `json:"tokens"`
+	User              *UserResponse `json:"user"`
+	Tokens            *TokenPair    `json:"tokens,omitempty"`
+	TwoFactorRequired bool          `json:"two_factor_required,omitempty"`
+	ChallengeToken    string        `json:"challenge_token,omitempty"`
 }
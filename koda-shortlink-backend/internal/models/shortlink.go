@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strconv"
 	"time"
 )
 
@@ -9,6 +10,8 @@ type ShortLink struct {
 	ShortCode   string     `json:"short_code" db:"short_code"`
 	Destination string     `json:"destination" db:"destination"`
 	UserID      *int64     `json:"user_id,omitempty" db:"user_id"`
+	WorkspaceID *int64     `json:"workspace_id,omitempty" db:"workspace_id"`
+	FolderID    *int64     `json:"folder_id,omitempty" db:"folder_id"`
 	Title       *string    `json:"title,omitempty" db:"title"`
 	Description *string    `json:"description,omitempty" db:"description"`
 	IsActive    bool       `json:"is_active" db:"is_active"`
@@ -16,6 +19,7 @@ type ShortLink struct {
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	BlockedAt   *time.Time `json:"blocked_at,omitempty" db:"blocked_at"`
 }
 
 type CreateLinkRequest struct {
@@ -24,27 +28,59 @@ type CreateLinkRequest struct {
 	Title       *string `json:"title,omitempty"`
 	Description *string `json:"description,omitempty"`
 	ExpiresAt   *string `json:"expires_at,omitempty"`
+	WorkspaceID *int64  `json:"workspace_id,omitempty"`
+	FolderID    *int64  `json:"folder_id,omitempty"`
 }
 
+// BulkLinkItem is one row of a bulk creation request, given either as an
+// element of a JSON array or as a CSV line with the same column names.
+type BulkLinkItem struct {
+	Destination string  `json:"destination"`
+	CustomSlug  *string `json:"custom_slug,omitempty"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	ExpiresAt   *string `json:"expires_at,omitempty"`
+}
+
+type BulkLinkResult struct {
+	Row     int           `json:"row"`
+	Success bool          `json:"success"`
+	Link    *LinkResponse `json:"link,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type BulkCreateLinksResponse struct {
+	Created int              `json:"created"`
+	Failed  int              `json:"failed"`
+	Results []BulkLinkResult `json:"results"`
+}
+
+// UpdateLinkRequest changes the given fields only. A folder_id of 0 takes
+// the link out of its folder.
 type UpdateLinkRequest struct {
 	Destination *string `json:"destination,omitempty" validate:"omitempty,url"`
 	Title       *string `json:"title,omitempty"`
 	Description *string `json:"description,omitempty"`
 	IsActive    *bool   `json:"is_active,omitempty"`
 	ExpiresAt   *string `json:"expires_at,omitempty"`
+	FolderID    *int64  `json:"folder_id,omitempty"`
 }
 
 type LinkResponse struct {
-	ID          int64      `json:"id"`
-	ShortCode   string     `json:"short_code"`
-	ShortURL    string     `json:"short_url"`
-	Destination string     `json:"destination"`
-	Title       *string    `json:"title,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	IsActive    bool       `json:"is_active"`
-	ClickCount  int64      `json:"click_count"`
-	CreatedAt   time.Time  `json:"created_at"`
-	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	ID          int64         `json:"id"`
+	ShortCode   string        `json:"short_code"`
+	ShortURL    string        `json:"short_url"`
+	Destination string        `json:"destination"`
+	WorkspaceID *int64        `json:"workspace_id,omitempty"`
+	FolderID    *int64        `json:"folder_id,omitempty"`
+	Title       *string       `json:"title,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Tags        []TagResponse `json:"tags,omitempty"`
+	IsActive    bool          `json:"is_active"`
+	BlockedAt   *time.Time    `json:"blocked_at,omitempty"`
+	ClickCount  int64         `json:"click_count"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty"`
 }
 
 type LinkListResponse struct {
@@ -55,17 +91,93 @@ type LinkListResponse struct {
 	TotalPages int            `json:"total_pages"`
 }
 
+// LinkCursorListResponse is the keyset-paginated form of LinkListResponse.
+// It skips the total count, which is what makes it cheap on large accounts.
+type LinkCursorListResponse struct {
+	Links      []LinkResponse `json:"links"`
+	PageSize   int            `json:"page_size"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
+}
+
+// SortValue returns the link's value for a LinkSort field in the form stored
+// in a Cursor.
+func (l *ShortLink) SortValue(sortBy string) string {
+	switch sortBy {
+	case LinkSortUpdatedAt:
+		return l.UpdatedAt.Format(CursorTimeLayout)
+	case LinkSortClicks:
+		return strconv.FormatInt(l.ClickCount, 10)
+	default:
+		return l.CreatedAt.Format(CursorTimeLayout)
+	}
+}
+
 func (l *ShortLink) ToResponse(baseURL string) *LinkResponse {
 	return &LinkResponse{
 		ID:          l.ID,
 		ShortCode:   l.ShortCode,
 		ShortURL:    baseURL + "/" + l.ShortCode,
 		Destination: l.Destination,
+		WorkspaceID: l.WorkspaceID,
+		FolderID:    l.FolderID,
 		Title:       l.Title,
 		Description: l.Description,
 		IsActive:    l.IsActive,
+		BlockedAt:   l.BlockedAt,
 		ClickCount:  l.ClickCount,
 		CreatedAt:   l.CreatedAt,
 		ExpiresAt:   l.ExpiresAt,
 	}
 }
+
+type AdminLinkResponse struct {
+	LinkResponse
+	UserID    *int64    `json:"user_id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (l *ShortLink) ToAdminResponse(baseURL string) *AdminLinkResponse {
+	return &AdminLinkResponse{
+		LinkResponse: *l.ToResponse(baseURL),
+		UserID:       l.UserID,
+		UpdatedAt:    l.UpdatedAt,
+	}
+}
+
+const (
+	LinkSortCreatedAt = "created_at"
+	LinkSortUpdatedAt = "updated_at"
+	LinkSortClicks    = "clicks"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// LinkListQuery narrows and orders a user's or workspace's own link list.
+// Nil filters are not applied. Links must carry every tag in TagIDs.
+type LinkListQuery struct {
+	Search    string
+	IsActive  *bool
+	Expired   *bool
+	HasExpiry *bool
+	FolderID  *int64
+	TagIDs    []int64
+	SortBy    string
+	SortOrder string
+}
+
+type LinkFilter struct {
+	Query    string
+	UserID   *int64
+	IsActive *bool
+	Blocked  *bool
+}
+
+type AdminLinkListResponse struct {
+	Links      []AdminLinkResponse `json:"links"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalPages int                 `json:"total_pages"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag labels links. Like a link, it belongs to a workspace when WorkspaceID
+// is set and to UserID otherwise, and only labels links with the same owner.
+type Tag struct {
+	ID          int64     `json:"id" db:"id"`
+	UserID      *int64    `json:"user_id,omitempty" db:"user_id"`
+	WorkspaceID *int64    `json:"workspace_id,omitempty" db:"workspace_id"`
+	Name        string    `json:"name" db:"name"`
+	Color       *string   `json:"color,omitempty" db:"color"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateTagRequest struct {
+	Name        string  `json:"name" binding:"required,min=1,max=50"`
+	Color       *string `json:"color,omitempty"`
+	WorkspaceID *int64  `json:"workspace_id,omitempty"`
+}
+
+type UpdateTagRequest struct {
+	Name  string  `json:"name" binding:"required,min=1,max=50"`
+	Color *string `json:"color,omitempty"`
+}
+
+type SetLinkTagsRequest struct {
+	TagIDs []int64 `json:"tag_ids" binding:"required"`
+}
+
+type TagResponse struct {
+	ID          int64   `json:"id"`
+	Name        string  `json:"name"`
+	Color       *string `json:"color,omitempty"`
+	WorkspaceID *int64  `json:"workspace_id,omitempty"`
+}
+
+type TagStats struct {
+	TagID       int64   `json:"tag_id"`
+	Name        string  `json:"name"`
+	Color       *string `json:"color,omitempty"`
+	TotalLinks  int64   `json:"total_links"`
+	TotalVisits int64   `json:"total_visits"`
+}
+
+func (t *Tag) ToResponse() *TagResponse {
+	return &TagResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Color:       t.Color,
+		WorkspaceID: t.WorkspaceID,
+	}
+}
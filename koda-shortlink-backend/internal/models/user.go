@@ -4,16 +4,25 @@ import (
 	"time"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID           int64      `json:"id" db:"id"`
-	FullName     string     `json:"full_name" db:"full_name" validate:"required,min=2,max=100"`
-	Email        string     `json:"email" db:"email" validate:"required,email"`
-	Password     string     `json:"-" db:"password"`
-	ProfileImage *string    `json:"profile_image,omitempty" db:"profile_image"`
-	IsActive     bool       `json:"is_active" db:"is_active"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ID              int64      `json:"id" db:"id"`
+	FullName        string     `json:"full_name" db:"full_name" validate:"required,min=2,max=100"`
+	Email           string     `json:"email" db:"email" validate:"required,email"`
+	Password        string     `json:"-" db:"password"`
+	ProfileImage    *string    `json:"profile_image,omitempty" db:"profile_image"`
+	IsActive        bool       `json:"is_active" db:"is_active"`
+	Role            string     `json:"role" db:"role"`
+	TOTPSecret      *string    `json:"-" db:"totp_secret"`
+	TOTPEnabled     bool       `json:"totp_enabled" db:"totp_enabled"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 type UserRegisterRequest struct {
@@ -29,11 +38,14 @@ type UserLoginRequest struct {
 }
 
 type UserResponse struct {
-	ID           int64     `json:"id"`
-	FullName     string    `json:"full_name"`
-	Email        string    `json:"email"`
-	ProfileImage *string   `json:"profile_image,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID            int64     `json:"id"`
+	FullName      string    `json:"full_name"`
+	Email         string    `json:"email"`
+	ProfileImage  *string   `json:"profile_image,omitempty"`
+	Role          string    `json:"role"`
+	TOTPEnabled   bool      `json:"totp_enabled"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type UpdateProfileRequest struct {
@@ -46,14 +58,107 @@ type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=8"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+	RefreshToken    string `json:"refresh_token,omitempty"`
 }
 
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:           u.ID,
-		FullName:     u.FullName,
-		Email:        u.Email,
-		ProfileImage: u.ProfileImage,
-		CreatedAt:    u.CreatedAt,
+		ID:            u.ID,
+		FullName:      u.FullName,
+		Email:         u.Email,
+		ProfileImage:  u.ProfileImage,
+		Role:          u.Role,
+		TOTPEnabled:   u.TOTPEnabled,
+		EmailVerified: u.EmailVerifiedAt != nil,
+		CreatedAt:     u.CreatedAt,
 	}
 }
+
+type AdminUserResponse struct {
+	UserResponse
+	IsActive  bool      `json:"is_active"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (u *User) ToAdminResponse() *AdminUserResponse {
+	return &AdminUserResponse{
+		UserResponse: *u.ToResponse(),
+		IsActive:     u.IsActive,
+		UpdatedAt:    u.UpdatedAt,
+	}
+}
+
+type UserFilter struct {
+	Query    string
+	Role     string
+	IsActive *bool
+}
+
+type AdminUserListResponse struct {
+	Users      []AdminUserResponse `json:"users"`
+	Total      int64               `json:"total"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalPages int                 `json:"total_pages"`
+}
+
+type SetActiveRequest struct {
+	IsActive *bool `json:"is_active" binding:"required"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorSetupRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type TwoFactorEnableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type EmailVerificationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=NewPassword"`
+}
+
+// DeleteAccountRequest is confirmed either with the current password or with
+// a token from the account deletion email.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
+type DeleteAccountResponse struct {
+	PurgeAt time.Time `json:"purge_at"`
+}
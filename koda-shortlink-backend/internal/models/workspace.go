@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	WorkspaceRoleOwner  = "owner"
+	WorkspaceRoleEditor = "editor"
+	WorkspaceRoleViewer = "viewer"
+)
+
+type Workspace struct {
+	ID        int64     `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedBy *int64    `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int64     `json:"workspace_id" db:"workspace_id"`
+	UserID      int64     `json:"user_id" db:"user_id"`
+	FullName    string    `json:"full_name" db:"full_name"`
+	Email       string    `json:"email" db:"email"`
+	Role        string    `json:"role" db:"role"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+}
+
+type UpdateWorkspaceRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=100"`
+}
+
+type AddWorkspaceMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type UpdateWorkspaceMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type WorkspaceResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID   int64     `json:"user_id"`
+	FullName string    `json:"full_name"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+func (w *Workspace) ToResponse(role string) *WorkspaceResponse {
+	return &WorkspaceResponse{
+		ID:        w.ID,
+		Name:      w.Name,
+		Role:      role,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func (m *WorkspaceMember) ToResponse() *WorkspaceMemberResponse {
+	return &WorkspaceMemberResponse{
+		UserID:   m.UserID,
+		FullName: m.FullName,
+		Email:    m.Email,
+		Role:     m.Role,
+		JoinedAt: m.CreatedAt,
+	}
+}
+
+func IsValidWorkspaceRole(role string) bool {
+	return role == WorkspaceRoleOwner || role == WorkspaceRoleEditor || role == WorkspaceRoleViewer
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"koda-shortlink-backend/internal/models"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(key.Scopes),
+		key.ExpiresAt,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+func (r *APIKeyRepository) FindActiveByHash(keyHash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	query := `
+		SELECT k.id, k.user_id, u.email, k.name, k.prefix, k.key_hash, k.scopes,
+		       k.last_used_at, k.expires_at, k.revoked_at, k.created_at
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1
+		  AND k.revoked_at IS NULL
+		  AND (k.expires_at IS NULL OR k.expires_at > $2)
+		  AND u.is_active = true AND u.deleted_at IS NULL
+	`
+
+	err := r.db.QueryRow(query, keyHash, time.Now()).Scan(
+		&key.ID,
+		&key.UserID,
+		&key.UserEmail,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&key.Scopes),
+		&key.LastUsedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("api key not found")
+	}
+
+	return key, err
+}
+
+// FindByUser lists the keys of a user that can still authenticate; revoked
+// and expired keys are left out.
+func (r *APIKeyRepository) FindByUser(userID int64) ([]models.APIKey, error) {
+	query := `
+		SELECT id, user_id, name, prefix, key_hash, scopes, last_used_at, expires_at, revoked_at, created_at
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.Prefix,
+			&key.KeyHash,
+			pq.Array(&key.Scopes),
+			&key.LastUsedAt,
+			&key.ExpiresAt,
+			&key.RevokedAt,
+			&key.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) CountActiveByUser(userID int64) (int64, error) {
+	var count int64
+	query := `
+		SELECT COUNT(*) FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > $2)
+	`
+	err := r.db.QueryRow(query, userID, time.Now()).Scan(&count)
+	return count, err
+}
+
+func (r *APIKeyRepository) Revoke(id int64, userID int64) error {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("api key not found")
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(id int64) error {
+	query := `
+		UPDATE api_keys SET last_used_at = $1
+		WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)
+	`
+	now := time.Now()
+	_, err := r.db.Exec(query, now, id, now.Add(-time.Minute))
+	return err
+}
@@ -2,13 +2,21 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
 	"koda-shortlink-backend/internal/models"
+	"strings"
+	"time"
 )
 
 type ClickRepository struct {
 	db *sql.DB
 }
 
+type groupedCount struct {
+	Value string
+	Count int64
+}
+
 func NewClickRepository(db *sql.DB) *ClickRepository {
 	return &ClickRepository{db: db}
 }
@@ -33,3 +41,303 @@ func (r *ClickRepository) Create(click *models.Click) error {
 		click.OS,
 	).Scan(&click.ID, &click.ClickedAt)
 }
+
+// ForEachByUser streams the click history of the user's personal links,
+// oldest first, without loading it into memory.
+func (r *ClickRepository) ForEachByUser(userID int64, fn func(*models.ClickRecord) error) error {
+	return r.forEach("sl.user_id = $1 AND sl.workspace_id IS NULL", []interface{}{userID}, fn)
+}
+
+// ForEachByUserBetween streams the clicks on a user's personal links made in
+// [from, to), oldest first.
+func (r *ClickRepository) ForEachByUserBetween(userID int64, from, to time.Time, fn func(*models.ClickRecord) error) error {
+	return r.forEach("sl.user_id = $1 AND sl.workspace_id IS NULL AND c.clicked_at >= $2 AND c.clicked_at < $3", []interface{}{userID, from, to}, fn)
+}
+
+func (r *ClickRepository) ForEachByWorkspaceBetween(workspaceID int64, from, to time.Time, fn func(*models.ClickRecord) error) error {
+	return r.forEach("sl.workspace_id = $1 AND c.clicked_at >= $2 AND c.clicked_at < $3", []interface{}{workspaceID, from, to}, fn)
+}
+
+func (r *ClickRepository) forEach(filter string, args []interface{}, fn func(*models.ClickRecord) error) error {
+	query := `
+		SELECT sl.short_code, c.id, c.link_id, c.ip_address, COALESCE(c.user_agent, ''), c.referer,
+		       c.country, c.city, c.device_type, c.browser, c.os, c.clicked_at
+		FROM clicks c
+		JOIN short_links sl ON c.link_id = sl.id
+		WHERE ` + filter + `
+		ORDER BY c.clicked_at, c.id
+	`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record := &models.ClickRecord{}
+		err := rows.Scan(
+			&record.ShortCode,
+			&record.ID,
+			&record.LinkID,
+			&record.IPAddress,
+			&record.UserAgent,
+			&record.Referer,
+			&record.Country,
+			&record.City,
+			&record.DeviceType,
+			&record.Browser,
+			&record.OS,
+			&record.ClickedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ForEachVisitor streams every recorded click with the owner of its link, in
+// no particular order.
+func (r *ClickRepository) ForEachVisitor(fn func(*models.ClickVisitor) error) error {
+	query := `
+		SELECT sl.user_id, sl.workspace_id, c.ip_address, COALESCE(c.user_agent, ''), c.clicked_at
+		FROM clicks c
+		JOIN short_links sl ON c.link_id = sl.id
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		visitor := &models.ClickVisitor{}
+		err := rows.Scan(
+			&visitor.UserID,
+			&visitor.WorkspaceID,
+			&visitor.IPAddress,
+			&visitor.UserAgent,
+			&visitor.ClickedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(visitor); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// FindByLinkAfter lists a link's clicks newest first, keyset-paginated on
+// (clicked_at, id) like ShortLinkRepository.FindByUserAfter.
+func (r *ClickRepository) FindByLinkAfter(linkID int64, cursor *models.Cursor, limit int) ([]models.Click, bool, error) {
+	conditions := []string{"link_id = $1"}
+	args := []interface{}{linkID}
+
+	backward := cursor != nil && cursor.Backward
+	direction, comparison := "DESC", "<"
+	if backward {
+		direction, comparison = "ASC", ">"
+	}
+
+	if cursor != nil {
+		args = append(args, cursor.Value, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(clicked_at, id) %s ($2::timestamp, $3)", comparison))
+	}
+
+	args = append(args, limit+1)
+	query := fmt.Sprintf(`
+		SELECT id, link_id, ip_address, COALESCE(user_agent, ''), referer, country, city,
+		       device_type, browser, os, clicked_at
+		FROM clicks
+		WHERE %s
+		ORDER BY clicked_at %s, id %s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), direction, direction, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	clicks := []models.Click{}
+	for rows.Next() {
+		var click models.Click
+		err := rows.Scan(
+			&click.ID,
+			&click.LinkID,
+			&click.IPAddress,
+			&click.UserAgent,
+			&click.Referer,
+			&click.Country,
+			&click.City,
+			&click.DeviceType,
+			&click.Browser,
+			&click.OS,
+			&click.ClickedAt,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+		clicks = append(clicks, click)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(clicks) > limit
+	if hasMore {
+		clicks = clicks[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(clicks)-1; i < j; i, j = i+1, j-1 {
+			clicks[i], clicks[j] = clicks[j], clicks[i]
+		}
+	}
+
+	return clicks, hasMore, nil
+}
+
+func (r *ClickRepository) GetAnalytics(linkID int64, from, to time.Time, limit int) (*models.ClickAnalytics, error) {
+	analytics := &models.ClickAnalytics{
+		ClicksByDay:  []models.ClicksByDay{},
+		TopCountries: []models.CountryStats{},
+		TopCities:    []models.CityStats{},
+		TopReferers:  []models.RefererStats{},
+		DeviceStats:  []models.DeviceStats{},
+		BrowserStats: []models.BrowserStats{},
+		OSStats:      []models.OSStats{},
+	}
+
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), COUNT(DISTINCT (ip_address, COALESCE(user_agent, ''))) FROM clicks
+		WHERE link_id = $1 AND clicked_at >= $2 AND clicked_at < $3
+	`, linkID, from, to).Scan(&analytics.TotalClicks, &analytics.UniqueClicks)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics.ClicksByDay, err = r.countByDay(linkID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	countries, err := r.countByColumn("country", linkID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range countries {
+		analytics.TopCountries = append(analytics.TopCountries, models.CountryStats{Country: c.Value, Count: c.Count})
+	}
+
+	cities, err := r.countByColumn("city", linkID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cities {
+		analytics.TopCities = append(analytics.TopCities, models.CityStats{City: c.Value, Count: c.Count})
+	}
+
+	referers, err := r.countByColumn("referer", linkID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range referers {
+		analytics.TopReferers = append(analytics.TopReferers, models.RefererStats{Referer: c.Value, Count: c.Count})
+	}
+
+	devices, err := r.countByColumn("device_type", linkID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range devices {
+		analytics.DeviceStats = append(analytics.DeviceStats, models.DeviceStats{DeviceType: c.Value, Count: c.Count})
+	}
+
+	browsers, err := r.countByColumn("browser", linkID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range browsers {
+		analytics.BrowserStats = append(analytics.BrowserStats, models.BrowserStats{Browser: c.Value, Count: c.Count})
+	}
+
+	systems, err := r.countByColumn("os", linkID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range systems {
+		analytics.OSStats = append(analytics.OSStats, models.OSStats{OS: c.Value, Count: c.Count})
+	}
+
+	return analytics, nil
+}
+
+func (r *ClickRepository) countByDay(linkID int64, from, to time.Time) ([]models.ClicksByDay, error) {
+	query := `
+		SELECT TO_CHAR(DATE(clicked_at), 'YYYY-MM-DD') as date, COUNT(*) as count,
+		       COUNT(DISTINCT (ip_address, COALESCE(user_agent, ''))) as unique_count
+		FROM clicks
+		WHERE link_id = $1 AND clicked_at >= $2 AND clicked_at < $3
+		GROUP BY DATE(clicked_at)
+		ORDER BY DATE(clicked_at)
+	`
+
+	rows, err := r.db.Query(query, linkID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []models.ClicksByDay{}
+	for rows.Next() {
+		var day models.ClicksByDay
+		if err := rows.Scan(&day.Date, &day.Count, &day.UniqueCount); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	return days, rows.Err()
+}
+
+func (r *ClickRepository) countByColumn(column string, linkID int64, from, to time.Time, limit int) ([]groupedCount, error) {
+	query := fmt.Sprintf(`
+		SELECT %[1]s, COUNT(*) as count
+		FROM clicks
+		WHERE link_id = $1 AND clicked_at >= $2 AND clicked_at < $3
+		  AND %[1]s IS NOT NULL AND %[1]s <> ''
+		GROUP BY %[1]s
+		ORDER BY count DESC, %[1]s
+		LIMIT $4
+	`, column)
+
+	rows, err := r.db.Query(query, linkID, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []groupedCount
+	for rows.Next() {
+		var gc groupedCount
+		if err := rows.Scan(&gc.Value, &gc.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, gc)
+	}
+
+	return counts, rows.Err()
+}
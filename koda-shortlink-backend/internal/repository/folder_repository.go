@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"koda-shortlink-backend/internal/models"
+)
+
+type FolderRepository struct {
+	db *sql.DB
+}
+
+func NewFolderRepository(db *sql.DB) *FolderRepository {
+	return &FolderRepository{db: db}
+}
+
+func (r *FolderRepository) Create(folder *models.Folder) error {
+	query := `
+		INSERT INTO folders (user_id, workspace_id, parent_id, name)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRow(query, folder.UserID, folder.WorkspaceID, folder.ParentID, folder.Name).
+		Scan(&folder.ID, &folder.CreatedAt, &folder.UpdatedAt)
+}
+
+func (r *FolderRepository) FindByID(id int64) (*models.Folder, error) {
+	folder := &models.Folder{}
+	query := `
+		SELECT id, user_id, workspace_id, parent_id, name, created_at, updated_at
+		FROM folders
+		WHERE id = $1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&folder.ID,
+		&folder.UserID,
+		&folder.WorkspaceID,
+		&folder.ParentID,
+		&folder.Name,
+		&folder.CreatedAt,
+		&folder.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("folder not found")
+	}
+
+	return folder, err
+}
+
+// FindByUser lists every personal folder of a user as a flat list ordered by
+// name; clients assemble the tree from parent_id.
+func (r *FolderRepository) FindByUser(userID int64) ([]models.Folder, error) {
+	return r.findMany(`user_id = $1 AND workspace_id IS NULL`, userID)
+}
+
+func (r *FolderRepository) FindByWorkspace(workspaceID int64) ([]models.Folder, error) {
+	return r.findMany(`workspace_id = $1`, workspaceID)
+}
+
+func (r *FolderRepository) Update(folder *models.Folder) error {
+	query := `
+		UPDATE folders
+		SET name = $1, parent_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+
+	result, err := r.db.Exec(query, folder.Name, folder.ParentID, folder.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("folder not found")
+	}
+
+	return nil
+}
+
+func (r *FolderRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM folders WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("folder not found")
+	}
+
+	return nil
+}
+
+// IsWithin reports whether folderID is ancestorID itself or one of its
+// descendants, which is what makes moving ancestorID under it a cycle.
+func (r *FolderRepository) IsWithin(folderID, ancestorID int64) (bool, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM folders WHERE id = $1
+			UNION
+			SELECT f.id, f.parent_id FROM folders f JOIN ancestors a ON f.id = a.parent_id
+		)
+		SELECT EXISTS(SELECT 1 FROM ancestors WHERE id = $2)
+	`
+
+	var within bool
+	err := r.db.QueryRow(query, folderID, ancestorID).Scan(&within)
+	return within, err
+}
+
+func (r *FolderRepository) findMany(filter string, ownerID int64) ([]models.Folder, error) {
+	query := `
+		SELECT id, user_id, workspace_id, parent_id, name, created_at, updated_at
+		FROM folders
+		WHERE ` + filter + `
+		ORDER BY name, id
+	`
+
+	rows, err := r.db.Query(query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := []models.Folder{}
+	for rows.Next() {
+		var folder models.Folder
+		err := rows.Scan(
+			&folder.ID,
+			&folder.UserID,
+			&folder.WorkspaceID,
+			&folder.ParentID,
+			&folder.Name,
+			&folder.CreatedAt,
+			&folder.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+
+	return folders, rows.Err()
+}
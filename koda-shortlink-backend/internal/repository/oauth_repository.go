@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"koda-shortlink-backend/internal/models"
+)
+
+type OAuthAccountRepository struct {
+	db *sql.DB
+}
+
+func NewOAuthAccountRepository(db *sql.DB) *OAuthAccountRepository {
+	return &OAuthAccountRepository{db: db}
+}
+
+func (r *OAuthAccountRepository) Create(account *models.OAuthAccount) error {
+	query := `
+		INSERT INTO oauth_accounts (user_id, provider, provider_id, email)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRow(
+		query,
+		account.UserID,
+		account.Provider,
+		account.ProviderID,
+		account.Email,
+	).Scan(&account.ID, &account.CreatedAt)
+}
+
+func (r *OAuthAccountRepository) FindByProvider(provider, providerID string) (*models.OAuthAccount, error) {
+	account := &models.OAuthAccount{}
+	query := `
+		SELECT id, user_id, provider, provider_id, email, created_at
+		FROM oauth_accounts
+		WHERE provider = $1 AND provider_id = $2
+	`
+
+	err := r.db.QueryRow(query, provider, providerID).Scan(
+		&account.ID,
+		&account.UserID,
+		&account.Provider,
+		&account.ProviderID,
+		&account.Email,
+		&account.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("oauth account not found")
+	}
+
+	return account, err
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "strings"
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes user input match literally inside a LIKE/ILIKE pattern.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
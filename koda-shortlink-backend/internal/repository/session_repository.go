@@ -2,10 +2,13 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"koda-shortlink-backend/internal/models"
 	"time"
 )
 
+var ErrSessionRotated = errors.New("session already rotated")
+
 type SessionRepository struct {
 	db *sql.DB
 }
@@ -16,50 +19,173 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 
 func (r *SessionRepository) Create(session *models.Session) error {
 	query := `
-		INSERT INTO sessions (user_id, refresh_token, user_agent, ip_address, expires_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO sessions (user_id, refresh_token_hash, family_id, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at
 	`
 
 	return r.db.QueryRow(
 		query,
 		session.UserID,
-		session.RefreshToken,
+		session.RefreshTokenHash,
+		session.FamilyID,
 		session.UserAgent,
 		session.IPAddress,
 		session.ExpiresAt,
 	).Scan(&session.ID, &session.CreatedAt)
 }
 
-func (r *SessionRepository) FindByRefreshToken(token string) (*models.Session, error) {
+func (r *SessionRepository) FindByRefreshTokenHash(tokenHash string) (*models.Session, error) {
 	session := &models.Session{}
 	query := `
-		SELECT id, user_id, refresh_token, user_agent, ip_address, expires_at, created_at
+		SELECT id, user_id, refresh_token_hash, family_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+		       expires_at, rotated_at, created_at
 		FROM sessions
-		WHERE refresh_token = $1 AND expires_at > $2
+		WHERE refresh_token_hash = $1 AND expires_at > $2
 	`
 
-	err := r.db.QueryRow(query, token, time.Now()).Scan(
+	err := r.db.QueryRow(query, tokenHash, time.Now()).Scan(
 		&session.ID,
 		&session.UserID,
-		&session.RefreshToken,
+		&session.RefreshTokenHash,
+		&session.FamilyID,
 		&session.UserAgent,
 		&session.IPAddress,
 		&session.ExpiresAt,
+		&session.RotatedAt,
 		&session.CreatedAt,
 	)
 
 	return session, err
 }
 
-func (r *SessionRepository) DeleteByRefreshToken(token string) error {
-	query := `DELETE FROM sessions WHERE refresh_token = $1`
-	_, err := r.db.Exec(query, token)
+// MarkRotated returns ErrSessionRotated when another request rotated the
+// session first.
+func (r *SessionRepository) MarkRotated(id int64) error {
+	query := `UPDATE sessions SET rotated_at = $1 WHERE id = $2 AND rotated_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrSessionRotated
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) DeleteByRefreshTokenHash(tokenHash string) error {
+	query := `
+		DELETE FROM sessions
+		WHERE family_id = (SELECT family_id FROM sessions WHERE refresh_token_hash = $1)
+	`
+	_, err := r.db.Exec(query, tokenHash)
 	return err
 }
 
-func (r *SessionRepository) DeleteExpiredSessions() error {
-	query := `DELETE FROM sessions WHERE expires_at < $1`
-	_, err := r.db.Exec(query, time.Now())
+func (r *SessionRepository) DeleteByFamily(familyID string) error {
+	query := `DELETE FROM sessions WHERE family_id = $1`
+	_, err := r.db.Exec(query, familyID)
+	return err
+}
+
+func (r *SessionRepository) FindActiveByUser(userID int64) ([]models.Session, error) {
+	query := `
+		SELECT id, user_id, refresh_token_hash, family_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''),
+		       expires_at, rotated_at, created_at
+		FROM sessions
+		WHERE user_id = $1 AND expires_at > $2 AND rotated_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Query(query, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var session models.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.RefreshTokenHash,
+			&session.FamilyID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.ExpiresAt,
+			&session.RotatedAt,
+			&session.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (r *SessionRepository) DeleteByID(id int64, userID int64) error {
+	query := `
+		DELETE FROM sessions
+		WHERE user_id = $2
+		  AND family_id = (SELECT family_id FROM sessions WHERE id = $1 AND user_id = $2)
+	`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("session not found")
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) DeleteByUser(userID int64) error {
+	query := `DELETE FROM sessions WHERE user_id = $1`
+	_, err := r.db.Exec(query, userID)
 	return err
 }
+
+func (r *SessionRepository) DeleteByUserExcept(userID int64, keepTokenHash string) error {
+	query := `
+		DELETE FROM sessions
+		WHERE user_id = $1
+		  AND family_id IS DISTINCT FROM (SELECT family_id FROM sessions WHERE refresh_token_hash = $2)
+	`
+	_, err := r.db.Exec(query, userID, keepTokenHash)
+	return err
+}
+
+// DeleteExpiredSessions removes sessions whose refresh token has expired,
+// rotated ones included. A rotated row is only kept to recognise its token
+// being replayed, and once expires_at passes the token no longer validates.
+func (r *SessionRepository) DeleteExpiredSessions() (int64, error) {
+	query := `DELETE FROM sessions WHERE expires_at < $1`
+	result, err := r.db.Exec(query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -3,8 +3,12 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"koda-shortlink-backend/internal/models"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 type ShortLinkRepository struct {
@@ -15,32 +19,83 @@ func NewShortLinkRepository(db *sql.DB) *ShortLinkRepository {
 	return &ShortLinkRepository{db: db}
 }
 
+// ErrShortCodeTaken is returned by CreateMany for a link whose short code was
+// claimed between the availability check and the insert.
+var ErrShortCodeTaken = errors.New("short code already taken")
+
+const insertShortLinkQuery = `
+	INSERT INTO short_links (short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, created_at, updated_at, click_count
+`
+
 func (r *ShortLinkRepository) Create(link *models.ShortLink) error {
-	query := `
-		INSERT INTO short_links (short_code, destination, user_id, title, description, is_active, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, created_at, updated_at, click_count
-	`
+	return r.db.QueryRow(insertShortLinkQuery, insertShortLinkArgs(link)...).
+		Scan(&link.ID, &link.CreatedAt, &link.UpdatedAt, &link.ClickCount)
+}
 
-	err := r.db.QueryRow(
-		query,
+// CreateMany inserts the links in a single transaction. Each insert runs in
+// its own savepoint so a failing row is reported in the returned slice, at
+// the same index, without aborting the others. The error return is reserved
+// for failures of the transaction itself, in which case nothing is created.
+func (r *ShortLinkRepository) CreateMany(links []*models.ShortLink) ([]error, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rowErrors := make([]error, len(links))
+	for i, link := range links {
+		if _, err := tx.Exec(`SAVEPOINT bulk_link`); err != nil {
+			return nil, err
+		}
+
+		err := tx.QueryRow(insertShortLinkQuery, insertShortLinkArgs(link)...).
+			Scan(&link.ID, &link.CreatedAt, &link.UpdatedAt, &link.ClickCount)
+		if err != nil {
+			if _, rbErr := tx.Exec(`ROLLBACK TO SAVEPOINT bulk_link`); rbErr != nil {
+				return nil, rbErr
+			}
+
+			if isUniqueViolation(err) {
+				err = ErrShortCodeTaken
+			}
+			rowErrors[i] = err
+			continue
+		}
+
+		if _, err := tx.Exec(`RELEASE SAVEPOINT bulk_link`); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return rowErrors, nil
+}
+
+func insertShortLinkArgs(link *models.ShortLink) []interface{} {
+	return []interface{}{
 		link.ShortCode,
 		link.Destination,
 		link.UserID,
+		link.WorkspaceID,
+		link.FolderID,
 		link.Title,
 		link.Description,
 		link.IsActive,
 		link.ExpiresAt,
-	).Scan(&link.ID, &link.CreatedAt, &link.UpdatedAt, &link.ClickCount)
-
-	return err
+	}
 }
 
 func (r *ShortLinkRepository) FindByShortCode(shortCode string) (*models.ShortLink, error) {
 	link := &models.ShortLink{}
 	query := `
-		SELECT id, short_code, destination, user_id, title, description, is_active, 
-		       click_count, created_at, updated_at, expires_at
+		SELECT id, short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, 
+		       click_count, created_at, updated_at, expires_at, blocked_at
 		FROM short_links
 		WHERE short_code = $1
 	`
@@ -50,6 +105,8 @@ func (r *ShortLinkRepository) FindByShortCode(shortCode string) (*models.ShortLi
 		&link.ShortCode,
 		&link.Destination,
 		&link.UserID,
+		&link.WorkspaceID,
+		&link.FolderID,
 		&link.Title,
 		&link.Description,
 		&link.IsActive,
@@ -57,6 +114,7 @@ func (r *ShortLinkRepository) FindByShortCode(shortCode string) (*models.ShortLi
 		&link.CreatedAt,
 		&link.UpdatedAt,
 		&link.ExpiresAt,
+		&link.BlockedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -69,8 +127,8 @@ func (r *ShortLinkRepository) FindByShortCode(shortCode string) (*models.ShortLi
 func (r *ShortLinkRepository) FindByID(id int64) (*models.ShortLink, error) {
 	link := &models.ShortLink{}
 	query := `
-		SELECT id, short_code, destination, user_id, title, description, is_active, 
-		       click_count, created_at, updated_at, expires_at
+		SELECT id, short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, 
+		       click_count, created_at, updated_at, expires_at, blocked_at
 		FROM short_links
 		WHERE id = $1
 	`
@@ -80,6 +138,8 @@ func (r *ShortLinkRepository) FindByID(id int64) (*models.ShortLink, error) {
 		&link.ShortCode,
 		&link.Destination,
 		&link.UserID,
+		&link.WorkspaceID,
+		&link.FolderID,
 		&link.Title,
 		&link.Description,
 		&link.IsActive,
@@ -87,6 +147,7 @@ func (r *ShortLinkRepository) FindByID(id int64) (*models.ShortLink, error) {
 		&link.CreatedAt,
 		&link.UpdatedAt,
 		&link.ExpiresAt,
+		&link.BlockedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -96,32 +157,349 @@ func (r *ShortLinkRepository) FindByID(id int64) (*models.ShortLink, error) {
 	return link, err
 }
 
-func (r *ShortLinkRepository) FindByUser(userID int64, page, pageSize int) ([]models.ShortLink, int64, error) {
-	offset := (page - 1) * pageSize
+// linkSortColumns maps each LinkSort field to its column and the type its
+// cursor value is cast to.
+var linkSortColumns = map[string]struct{ column, cast string }{
+	models.LinkSortCreatedAt: {"created_at", "timestamp"},
+	models.LinkSortUpdatedAt: {"updated_at", "timestamp"},
+	models.LinkSortClicks:    {"click_count", "bigint"},
+}
+
+// FindByUser lists the personal links of a user; links they created in a
+// workspace are listed with the workspace.
+func (r *ShortLinkRepository) FindByUser(userID int64, query *models.LinkListQuery, page, pageSize int) ([]models.ShortLink, int64, error) {
+	return r.findPage("user_id = $1 AND workspace_id IS NULL", userID, query, page, pageSize)
+}
+
+func (r *ShortLinkRepository) FindByWorkspace(workspaceID int64, query *models.LinkListQuery, page, pageSize int) ([]models.ShortLink, int64, error) {
+	return r.findPage("workspace_id = $1", workspaceID, query, page, pageSize)
+}
+
+// FindByUserAfter is the keyset-paginated form of FindByUser. It returns up
+// to limit links following cursor in the traversal direction, or from the
+// start when cursor is nil, and whether more links lie beyond them.
+func (r *ShortLinkRepository) FindByUserAfter(userID int64, query *models.LinkListQuery, cursor *models.Cursor, limit int) ([]models.ShortLink, bool, error) {
+	return r.findAfter("user_id = $1 AND workspace_id IS NULL", userID, query, cursor, limit)
+}
+
+func (r *ShortLinkRepository) FindByWorkspaceAfter(workspaceID int64, query *models.LinkListQuery, cursor *models.Cursor, limit int) ([]models.ShortLink, bool, error) {
+	return r.findAfter("workspace_id = $1", workspaceID, query, cursor, limit)
+}
+
+// findPage lists the links matched by ownerFilter, a condition that takes the
+// owner ID as $1, narrowed and sorted by query. The search is a substring
+// match served by the trigram indexes on the searched columns.
+func (r *ShortLinkRepository) findPage(ownerFilter string, ownerID int64, query *models.LinkListQuery, page, pageSize int) ([]models.ShortLink, int64, error) {
+	conditions, args := linkListConditions(ownerFilter, ownerID, query)
+	where := strings.Join(conditions, " AND ")
 
 	var total int64
-	countQuery := `SELECT COUNT(*) FROM short_links WHERE user_id = $1`
-	err := r.db.QueryRow(countQuery, userID).Scan(&total)
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM short_links WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort := linkSortColumns[query.SortBy]
+	if sort.column == "" {
+		sort = linkSortColumns[models.LinkSortCreatedAt]
+	}
+	direction := "DESC"
+	if query.SortOrder == models.SortOrderAsc {
+		direction = "ASC"
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	selectQuery := fmt.Sprintf(`
+		SELECT id, short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, 
+		       click_count, created_at, updated_at, expires_at, blocked_at
+		FROM short_links
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT $%d OFFSET $%d
+	`, where, sort.column, direction, direction, len(args)-1, len(args))
+
+	links, err := r.queryLinks(selectQuery, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 
+	return links, total, nil
+}
+
+// findAfter seeks past the cursor with a row comparison on (sort column, id)
+// instead of an OFFSET, so every page costs the same however deep it is.
+// Backward pages are read in reverse order and flipped before returning.
+func (r *ShortLinkRepository) findAfter(ownerFilter string, ownerID int64, query *models.LinkListQuery, cursor *models.Cursor, limit int) ([]models.ShortLink, bool, error) {
+	conditions, args := linkListConditions(ownerFilter, ownerID, query)
+
+	sort := linkSortColumns[query.SortBy]
+	if sort.column == "" {
+		sort = linkSortColumns[models.LinkSortCreatedAt]
+	}
+	descending := query.SortOrder != models.SortOrderAsc
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		descending = !descending
+	}
+
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if cursor != nil {
+		args = append(args, cursor.Value, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)", sort.column, comparison, len(args)-1, sort.cast, len(args)))
+	}
+
+	args = append(args, limit+1)
+	selectQuery := fmt.Sprintf(`
+		SELECT id, short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, 
+		       click_count, created_at, updated_at, expires_at, blocked_at
+		FROM short_links
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), sort.column, direction, direction, len(args))
+
+	links, err := r.queryLinks(selectQuery, args...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(links) > limit
+	if hasMore {
+		links = links[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(links)-1; i < j; i, j = i+1, j-1 {
+			links[i], links[j] = links[j], links[i]
+		}
+	}
+
+	return links, hasMore, nil
+}
+
+// linkListConditions translates the filters of a link list query into WHERE
+// conditions, starting from ownerFilter which takes the owner ID as $1.
+func linkListConditions(ownerFilter string, ownerID int64, query *models.LinkListQuery) ([]string, []interface{}) {
+	conditions := []string{ownerFilter}
+	args := []interface{}{ownerID}
+
+	if query.Search != "" {
+		args = append(args, "%"+escapeLike(query.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf("(short_code ILIKE $%d OR destination ILIKE $%d OR title ILIKE $%d OR description ILIKE $%d)", len(args), len(args), len(args), len(args)))
+	}
+	if query.IsActive != nil {
+		args = append(args, *query.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	if query.Expired != nil {
+		args = append(args, *query.Expired)
+		conditions = append(conditions, fmt.Sprintf("(expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP) = $%d", len(args)))
+	}
+	if query.HasExpiry != nil {
+		args = append(args, *query.HasExpiry)
+		conditions = append(conditions, fmt.Sprintf("(expires_at IS NOT NULL) = $%d", len(args)))
+	}
+	if query.FolderID != nil {
+		args = append(args, *query.FolderID)
+		conditions = append(conditions, fmt.Sprintf("folder_id = $%d", len(args)))
+	}
+	if len(query.TagIDs) > 0 {
+		args = append(args, pq.Array(query.TagIDs), len(query.TagIDs))
+		conditions = append(conditions, fmt.Sprintf(`id IN (
+			SELECT link_id FROM link_tags WHERE tag_id = ANY($%d) GROUP BY link_id HAVING COUNT(*) = $%d
+		)`, len(args)-1, len(args)))
+	}
+
+	return conditions, args
+}
+
+func (r *ShortLinkRepository) queryLinks(query string, args ...interface{}) ([]models.ShortLink, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []models.ShortLink
+	for rows.Next() {
+		var link models.ShortLink
+		err := rows.Scan(
+			&link.ID,
+			&link.ShortCode,
+			&link.Destination,
+			&link.UserID,
+			&link.WorkspaceID,
+			&link.FolderID,
+			&link.Title,
+			&link.Description,
+			&link.IsActive,
+			&link.ClickCount,
+			&link.CreatedAt,
+			&link.UpdatedAt,
+			&link.ExpiresAt,
+			&link.BlockedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// FindAllByUser returns every personal link of the user, oldest first.
+// Workspace links belong to the workspace and are left out.
+func (r *ShortLinkRepository) FindAllByUser(userID int64) ([]models.ShortLink, error) {
+	query := `
+		SELECT id, short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, 
+		       click_count, created_at, updated_at, expires_at, blocked_at
+		FROM short_links
+		WHERE user_id = $1 AND workspace_id IS NULL
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.ShortLink{}
+	for rows.Next() {
+		var link models.ShortLink
+		err := rows.Scan(
+			&link.ID,
+			&link.ShortCode,
+			&link.Destination,
+			&link.UserID,
+			&link.WorkspaceID,
+			&link.FolderID,
+			&link.Title,
+			&link.Description,
+			&link.IsActive,
+			&link.ClickCount,
+			&link.CreatedAt,
+			&link.UpdatedAt,
+			&link.ExpiresAt,
+			&link.BlockedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// ForEachByUser streams the personal links of a user, oldest first, calling
+// fn for each row as it is read so large accounts are never held in memory.
+func (r *ShortLinkRepository) ForEachByUser(userID int64, fn func(*models.ShortLink) error) error {
+	return r.forEach("user_id = $1 AND workspace_id IS NULL", userID, fn)
+}
+
+func (r *ShortLinkRepository) ForEachByWorkspace(workspaceID int64, fn func(*models.ShortLink) error) error {
+	return r.forEach("workspace_id = $1", workspaceID, fn)
+}
+
+func (r *ShortLinkRepository) forEach(ownerFilter string, ownerID int64, fn func(*models.ShortLink) error) error {
 	query := `
-		SELECT id, short_code, destination, user_id, title, description, is_active, 
-		       click_count, created_at, updated_at, expires_at
+		SELECT id, short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, 
+		       click_count, created_at, updated_at, expires_at, blocked_at
 		FROM short_links
-		WHERE user_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		WHERE ` + ownerFilter + `
+		ORDER BY created_at, id
 	`
 
-	rows, err := r.db.Query(query, userID, pageSize, offset)
+	rows, err := r.db.Query(query, ownerID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		link := &models.ShortLink{}
+		err := rows.Scan(
+			&link.ID,
+			&link.ShortCode,
+			&link.Destination,
+			&link.UserID,
+			&link.WorkspaceID,
+			&link.FolderID,
+			&link.Title,
+			&link.Description,
+			&link.IsActive,
+			&link.ClickCount,
+			&link.CreatedAt,
+			&link.UpdatedAt,
+			&link.ExpiresAt,
+			&link.BlockedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Search lists links across all users matching the filter, newest first.
+// Query matches the short code, destination or title case-insensitively.
+func (r *ShortLinkRepository) Search(filter *models.LinkFilter, page, pageSize int) ([]models.ShortLink, int64, error) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	if filter.Query != "" {
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+		conditions = append(conditions, fmt.Sprintf("(short_code ILIKE $%d OR destination ILIKE $%d OR title ILIKE $%d)", len(args), len(args), len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	if filter.Blocked != nil {
+		args = append(args, *filter.Blocked)
+		conditions = append(conditions, fmt.Sprintf("(blocked_at IS NOT NULL) = $%d", len(args)))
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM short_links WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	query := fmt.Sprintf(`
+		SELECT id, short_code, destination, user_id, workspace_id, folder_id, title, description, is_active, 
+		       click_count, created_at, updated_at, expires_at, blocked_at
+		FROM short_links
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var links []models.ShortLink
+	links := []models.ShortLink{}
 	for rows.Next() {
 		var link models.ShortLink
 		err := rows.Scan(
@@ -129,6 +507,8 @@ func (r *ShortLinkRepository) FindByUser(userID int64, page, pageSize int) ([]mo
 			&link.ShortCode,
 			&link.Destination,
 			&link.UserID,
+			&link.WorkspaceID,
+			&link.FolderID,
 			&link.Title,
 			&link.Description,
 			&link.IsActive,
@@ -136,6 +516,7 @@ func (r *ShortLinkRepository) FindByUser(userID int64, page, pageSize int) ([]mo
 			&link.CreatedAt,
 			&link.UpdatedAt,
 			&link.ExpiresAt,
+			&link.BlockedAt,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -143,15 +524,43 @@ func (r *ShortLinkRepository) FindByUser(userID int64, page, pageSize int) ([]mo
 		links = append(links, link)
 	}
 
-	return links, total, nil
+	return links, total, rows.Err()
+}
+
+// SetBlocked disables a link for abuse handling, or lifts the block. The
+// block is kept apart from is_active, which stays the owner's to switch, so
+// lifting it restores whatever state the owner left the link in.
+func (r *ShortLinkRepository) SetBlocked(id int64, blocked bool) error {
+	query := `
+		UPDATE short_links
+		SET blocked_at = CASE WHEN $1 THEN CURRENT_TIMESTAMP ELSE NULL END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+
+	result, err := r.db.Exec(query, blocked, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("short link not found")
+	}
+
+	return nil
 }
 
 func (r *ShortLinkRepository) Update(link *models.ShortLink) error {
 	query := `
 		UPDATE short_links
 		SET destination = $1, title = $2, description = $3, is_active = $4, 
-		    expires_at = $5, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $6
+		    expires_at = $5, folder_id = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
 	`
 
 	result, err := r.db.Exec(
@@ -161,6 +570,7 @@ func (r *ShortLinkRepository) Update(link *models.ShortLink) error {
 		link.Description,
 		link.IsActive,
 		link.ExpiresAt,
+		link.FolderID,
 		link.ID,
 	)
 	if err != nil {
@@ -179,10 +589,10 @@ func (r *ShortLinkRepository) Update(link *models.ShortLink) error {
 	return nil
 }
 
-func (r *ShortLinkRepository) Delete(id int64, userID int64) error {
-	query := `DELETE FROM short_links WHERE id = $1 AND user_id = $2`
+func (r *ShortLinkRepository) Delete(id int64) error {
+	query := `DELETE FROM short_links WHERE id = $1`
 
-	result, err := r.db.Exec(query, id, userID)
+	result, err := r.db.Exec(query, id)
 	if err != nil {
 		return err
 	}
@@ -193,7 +603,7 @@ func (r *ShortLinkRepository) Delete(id int64, userID int64) error {
 	}
 
 	if rows == 0 {
-		return errors.New("short link not found or unauthorized")
+		return errors.New("short link not found")
 	}
 
 	return nil
@@ -213,9 +623,19 @@ func (r *ShortLinkRepository) IncrementClickCount(linkID int64) error {
 }
 
 func (r *ShortLinkRepository) GetDashboardStats(userID int64) (*models.DashboardStats, error) {
+	return r.dashboardStats("sl.user_id = $1 AND sl.workspace_id IS NULL", userID)
+}
+
+func (r *ShortLinkRepository) GetWorkspaceDashboardStats(workspaceID int64) (*models.DashboardStats, error) {
+	return r.dashboardStats("sl.workspace_id = $1", workspaceID)
+}
+
+// dashboardStats aggregates the links matched by ownerFilter, a condition on
+// short_links aliased as sl that takes the owner ID as $1.
+func (r *ShortLinkRepository) dashboardStats(ownerFilter string, ownerID int64) (*models.DashboardStats, error) {
 	stats := &models.DashboardStats{}
 
-	err := r.db.QueryRow(`SELECT COUNT(*) FROM short_links WHERE user_id = $1`, userID).Scan(&stats.TotalLinks)
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM short_links sl WHERE `+ownerFilter, ownerID).Scan(&stats.TotalLinks)
 	if err != nil {
 		return nil, err
 	}
@@ -223,9 +643,8 @@ func (r *ShortLinkRepository) GetDashboardStats(userID int64) (*models.Dashboard
 	query := `
 		SELECT COALESCE(SUM(sl.click_count), 0)
 		FROM short_links sl
-		WHERE sl.user_id = $1
-	`
-	err = r.db.QueryRow(query, userID).Scan(&stats.TotalVisits)
+		WHERE ` + ownerFilter
+	err = r.db.QueryRow(query, ownerID).Scan(&stats.TotalVisits)
 	if err != nil {
 		return nil, err
 	}
@@ -234,17 +653,17 @@ func (r *ShortLinkRepository) GetDashboardStats(userID int64) (*models.Dashboard
 		stats.AvgClickRate = float64(stats.TotalVisits) / float64(stats.TotalLinks)
 	}
 
-	sevenDaysAgo := time.Now().AddDate(0, 0, -7)
+	sevenDaysAgo := time.Now().UTC().AddDate(0, 0, -7)
 	visitQuery := `
-		SELECT DATE(c.clicked_at) as date, COUNT(*) as visits
+		SELECT TO_CHAR(DATE(c.clicked_at), 'YYYY-MM-DD') as date, COUNT(*) as visits
 		FROM clicks c
 		JOIN short_links sl ON c.link_id = sl.id
-		WHERE sl.user_id = $1 AND c.clicked_at >= $2
+		WHERE ` + ownerFilter + ` AND c.clicked_at >= $2
 		GROUP BY DATE(c.clicked_at)
 		ORDER BY date
 	`
 
-	rows, err := r.db.Query(visitQuery, userID, sevenDaysAgo)
+	rows, err := r.db.Query(visitQuery, ownerID, sevenDaysAgo)
 	if err != nil {
 		return nil, err
 	}
@@ -259,24 +678,50 @@ func (r *ShortLinkRepository) GetDashboardStats(userID int64) (*models.Dashboard
 		stats.Last7DaysVisits = append(stats.Last7DaysVisits, chart)
 	}
 
-	twoWeeksAgo := time.Now().AddDate(0, 0, -14)
+	twoWeeksAgo := time.Now().UTC().AddDate(0, 0, -14)
 	var lastWeekVisits, thisWeekVisits int64
 
 	r.db.QueryRow(`
 		SELECT COUNT(*) FROM clicks c
 		JOIN short_links sl ON c.link_id = sl.id
-		WHERE sl.user_id = $1 AND c.clicked_at >= $2 AND c.clicked_at < $3
-	`, userID, twoWeeksAgo, sevenDaysAgo).Scan(&lastWeekVisits)
+		WHERE `+ownerFilter+` AND c.clicked_at >= $2 AND c.clicked_at < $3
+	`, ownerID, twoWeeksAgo, sevenDaysAgo).Scan(&lastWeekVisits)
 
 	r.db.QueryRow(`
 		SELECT COUNT(*) FROM clicks c
 		JOIN short_links sl ON c.link_id = sl.id
-		WHERE sl.user_id = $1 AND c.clicked_at >= $2
-	`, userID, sevenDaysAgo).Scan(&thisWeekVisits)
+		WHERE `+ownerFilter+` AND c.clicked_at >= $2
+	`, ownerID, sevenDaysAgo).Scan(&thisWeekVisits)
 
 	if lastWeekVisits > 0 {
 		stats.VisitsGrowth = ((float64(thisWeekVisits) - float64(lastWeekVisits)) / float64(lastWeekVisits)) * 100
 	}
 
-	return stats, nil
+	tagQuery := `
+		SELECT t.id, t.name, t.color, COUNT(sl.id), COALESCE(SUM(sl.click_count), 0) AS visits
+		FROM link_tags lt
+		JOIN tags t ON lt.tag_id = t.id
+		JOIN short_links sl ON lt.link_id = sl.id
+		WHERE ` + ownerFilter + `
+		GROUP BY t.id, t.name, t.color
+		ORDER BY visits DESC, t.name
+	`
+
+	tagRows, err := r.db.Query(tagQuery, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer tagRows.Close()
+
+	stats.Tags = []models.TagStats{}
+	for tagRows.Next() {
+		var tagStats models.TagStats
+		err := tagRows.Scan(&tagStats.TagID, &tagStats.Name, &tagStats.Color, &tagStats.TotalLinks, &tagStats.TotalVisits)
+		if err != nil {
+			return nil, err
+		}
+		stats.Tags = append(stats.Tags, tagStats)
+	}
+
+	return stats, tagRows.Err()
 }
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"koda-shortlink-backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ErrTagExists is returned when the owner already has a tag with the same
+// name, compared case-insensitively.
+var ErrTagExists = errors.New("tag already exists")
+
+type TagRepository struct {
+	db *sql.DB
+}
+
+func NewTagRepository(db *sql.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) Create(tag *models.Tag) error {
+	query := `
+		INSERT INTO tags (user_id, workspace_id, name, color)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(query, tag.UserID, tag.WorkspaceID, tag.Name, tag.Color).
+		Scan(&tag.ID, &tag.CreatedAt, &tag.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrTagExists
+	}
+
+	return err
+}
+
+func (r *TagRepository) FindByID(id int64) (*models.Tag, error) {
+	tag := &models.Tag{}
+	query := `
+		SELECT id, user_id, workspace_id, name, color, created_at, updated_at
+		FROM tags
+		WHERE id = $1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&tag.ID,
+		&tag.UserID,
+		&tag.WorkspaceID,
+		&tag.Name,
+		&tag.Color,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("tag not found")
+	}
+
+	return tag, err
+}
+
+// FindByUser lists the personal tags of a user by name.
+func (r *TagRepository) FindByUser(userID int64) ([]models.Tag, error) {
+	return r.findMany(`user_id = $1 AND workspace_id IS NULL`, userID)
+}
+
+func (r *TagRepository) FindByWorkspace(workspaceID int64) ([]models.Tag, error) {
+	return r.findMany(`workspace_id = $1`, workspaceID)
+}
+
+func (r *TagRepository) FindByIDs(ids []int64) ([]models.Tag, error) {
+	return r.findMany(`id = ANY($1)`, pq.Array(ids))
+}
+
+// FindByLinks returns the tags of each of the given links, keyed by link ID.
+func (r *TagRepository) FindByLinks(linkIDs []int64) (map[int64][]models.Tag, error) {
+	query := `
+		SELECT lt.link_id, t.id, t.user_id, t.workspace_id, t.name, t.color, t.created_at, t.updated_at
+		FROM link_tags lt
+		JOIN tags t ON lt.tag_id = t.id
+		WHERE lt.link_id = ANY($1)
+		ORDER BY t.name
+	`
+
+	rows, err := r.db.Query(query, pq.Array(linkIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[int64][]models.Tag)
+	for rows.Next() {
+		var linkID int64
+		var tag models.Tag
+		err := rows.Scan(
+			&linkID,
+			&tag.ID,
+			&tag.UserID,
+			&tag.WorkspaceID,
+			&tag.Name,
+			&tag.Color,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tags[linkID] = append(tags[linkID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *TagRepository) Update(tag *models.Tag) error {
+	query := `
+		UPDATE tags
+		SET name = $1, color = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`
+
+	result, err := r.db.Exec(query, tag.Name, tag.Color, tag.ID)
+	if isUniqueViolation(err) {
+		return ErrTagExists
+	}
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("tag not found")
+	}
+
+	return nil
+}
+
+func (r *TagRepository) Delete(id int64) error {
+	result, err := r.db.Exec(`DELETE FROM tags WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("tag not found")
+	}
+
+	return nil
+}
+
+// SetLinkTags replaces the tags of a link with tagIDs.
+func (r *TagRepository) SetLinkTags(linkID int64, tagIDs []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM link_tags WHERE link_id = $1`, linkID); err != nil {
+		return err
+	}
+
+	if len(tagIDs) > 0 {
+		_, err := tx.Exec(
+			`INSERT INTO link_tags (link_id, tag_id) SELECT $1, UNNEST($2::bigint[]) ON CONFLICT DO NOTHING`,
+			linkID, pq.Array(tagIDs),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *TagRepository) findMany(filter string, arg interface{}) ([]models.Tag, error) {
+	query := `
+		SELECT id, user_id, workspace_id, name, color, created_at, updated_at
+		FROM tags
+		WHERE ` + filter + `
+		ORDER BY name
+	`
+
+	rows, err := r.db.Query(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		err := rows.Scan(
+			&tag.ID,
+			&tag.UserID,
+			&tag.WorkspaceID,
+			&tag.Name,
+			&tag.Color,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
@@ -3,7 +3,12 @@ package repository
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"koda-shortlink-backend/internal/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type UserRepository struct {
@@ -16,9 +21,9 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 
 func (r *UserRepository) Create(user *models.User) error {
 	query := `
-		INSERT INTO users (full_name, email, password, is_active)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at
+		INSERT INTO users (full_name, email, password, profile_image, is_active, email_verified_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, role, created_at, updated_at
 	`
 
 	err := r.db.QueryRow(
@@ -26,8 +31,10 @@ func (r *UserRepository) Create(user *models.User) error {
 		user.FullName,
 		user.Email,
 		user.Password,
+		user.ProfileImage,
 		user.IsActive,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		user.EmailVerifiedAt,
+	).Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 
 	return err
 }
@@ -35,7 +42,8 @@ func (r *UserRepository) Create(user *models.User) error {
 func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, full_name, email, password, profile_image, is_active, created_at, updated_at
+		SELECT id, full_name, email, password, profile_image, is_active, role, totp_secret, totp_enabled,
+		       email_verified_at, created_at, updated_at
 		FROM users
 		WHERE email = $1 AND deleted_at IS NULL
 	`
@@ -47,6 +55,10 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 		&user.Password,
 		&user.ProfileImage,
 		&user.IsActive,
+		&user.Role,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
+		&user.EmailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -61,7 +73,8 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 func (r *UserRepository) FindByID(id int64) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, full_name, email, password, profile_image, is_active, created_at, updated_at
+		SELECT id, full_name, email, password, profile_image, is_active, role, totp_secret, totp_enabled,
+		       email_verified_at, created_at, updated_at
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -73,6 +86,10 @@ func (r *UserRepository) FindByID(id int64) (*models.User, error) {
 		&user.Password,
 		&user.ProfileImage,
 		&user.IsActive,
+		&user.Role,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
+		&user.EmailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -84,10 +101,80 @@ func (r *UserRepository) FindByID(id int64) (*models.User, error) {
 	return user, err
 }
 
+// Search lists users matching the filter, newest first. Query matches the
+// email or full name case-insensitively.
+func (r *UserRepository) Search(filter *models.UserFilter, page, pageSize int) ([]models.User, int64, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := []interface{}{}
+
+	if filter.Query != "" {
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+		conditions = append(conditions, fmt.Sprintf("(email ILIKE $%d OR full_name ILIKE $%d)", len(args), len(args)))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM users WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, pageSize, (page-1)*pageSize)
+	query := fmt.Sprintf(`
+		SELECT id, full_name, email, password, profile_image, is_active, role, totp_secret, totp_enabled,
+		       email_verified_at, created_at, updated_at
+		FROM users
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)-1, len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID,
+			&user.FullName,
+			&user.Email,
+			&user.Password,
+			&user.ProfileImage,
+			&user.IsActive,
+			&user.Role,
+			&user.TOTPSecret,
+			&user.TOTPEnabled,
+			&user.EmailVerifiedAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	query := `
 		UPDATE users
-		SET full_name = $1, email = $2, profile_image = $3, updated_at = CURRENT_TIMESTAMP
+		SET full_name = $1, email = $2, profile_image = $3, updated_at = CURRENT_TIMESTAMP,
+		    email_verified_at = CASE WHEN email = $2 THEN email_verified_at ELSE NULL END
 		WHERE id = $4 AND deleted_at IS NULL
 	`
 
@@ -132,6 +219,246 @@ func (r *UserRepository) UpdatePassword(userID int64, hashedPassword string) err
 	return nil
 }
 
+func (r *UserRepository) UpdateActive(userID int64, active bool) error {
+	query := `
+		UPDATE users
+		SET is_active = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, active, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// UpdateRoleByEmail sets the role of the live account registered to email.
+func (r *UserRepository) UpdateRoleByEmail(email, role string) error {
+	query := `
+		UPDATE users
+		SET role = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE email = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, role, email)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *UserRepository) UpdateTOTP(userID int64, secret *string, enabled bool) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $1, totp_enabled = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, secret, enabled, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *UserRepository) MarkEmailVerified(userID int64, email string) error {
+	query := `
+		UPDATE users
+		SET email_verified_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, userID, email)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// SoftDelete marks the user deleted and, in the same transaction, signs out
+// every session, unlinks OAuth identities so the provider account can sign up
+// again and disables the user's personal links along with the links of
+// workspaces no other live member is left in. It returns the short codes of
+// the disabled links so their cached destinations can be evicted.
+func (r *UserRepository) SoftDelete(userID int64) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE users
+		SET deleted_at = CURRENT_TIMESTAMP, is_active = false, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rows == 0 {
+		return nil, errors.New("user not found")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM sessions WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM oauth_accounts WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+
+	linkRows, err := tx.Query(`
+		UPDATE short_links
+		SET is_active = false, updated_at = CURRENT_TIMESTAMP
+		WHERE is_active = true
+		  AND (
+		      (user_id = $1 AND workspace_id IS NULL)
+		      OR workspace_id IN (
+		          SELECT m.workspace_id FROM workspace_members m
+		          WHERE m.user_id = $1
+		            AND NOT EXISTS (
+		                SELECT 1 FROM workspace_members o
+		                JOIN users u ON u.id = o.user_id
+		                WHERE o.workspace_id = m.workspace_id AND o.user_id <> $1
+		                  AND u.deleted_at IS NULL
+		            )
+		      )
+		  )
+		RETURNING short_code
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer linkRows.Close()
+
+	var shortCodes []string
+	for linkRows.Next() {
+		var shortCode string
+		if err := linkRows.Scan(&shortCode); err != nil {
+			return nil, err
+		}
+		shortCodes = append(shortCodes, shortCode)
+	}
+	if err := linkRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shortCodes, tx.Commit()
+}
+
+// PurgeDeleted permanently removes users soft-deleted before the cutoff
+// together with their personal links. Clicks, sessions and the remaining
+// per-user rows go through ON DELETE CASCADE; workspace links stay with their
+// workspace, and workspaces the purge leaves without members are removed.
+func (r *UserRepository) PurgeDeleted(before time.Time) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM short_links
+		WHERE workspace_id IS NULL
+		  AND user_id IN (SELECT id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1)
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT DISTINCT workspace_id FROM workspace_members
+		WHERE user_id IN (SELECT id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1)
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	var workspaceIDs []int64
+	for rows.Next() {
+		var workspaceID int64
+		if err := rows.Scan(&workspaceID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		workspaceIDs = append(workspaceIDs, workspaceID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(workspaceIDs) > 0 {
+		_, err = tx.Exec(`
+			DELETE FROM workspaces w
+			WHERE w.id = ANY($1)
+			  AND NOT EXISTS (SELECT 1 FROM workspace_members m WHERE m.workspace_id = w.id)
+		`, pq.Array(workspaceIDs))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return purged, tx.Commit()
+}
+
 func (r *UserRepository) EmailExists(email string) (bool, error) {
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL)`
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"koda-shortlink-backend/internal/models"
+)
+
+var ErrNotWorkspaceMember = errors.New("workspace member not found")
+
+type WorkspaceRepository struct {
+	db *sql.DB
+}
+
+func NewWorkspaceRepository(db *sql.DB) *WorkspaceRepository {
+	return &WorkspaceRepository{db: db}
+}
+
+// Create inserts the workspace and makes its creator the first owner.
+func (r *WorkspaceRepository) Create(workspace *models.Workspace) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO workspaces (name, created_by)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = tx.QueryRow(query, workspace.Name, workspace.CreatedBy).Scan(&workspace.ID, &workspace.CreatedAt, &workspace.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO workspace_members (workspace_id, user_id, role) VALUES ($1, $2, $3)`,
+		workspace.ID, workspace.CreatedBy, models.WorkspaceRoleOwner,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *WorkspaceRepository) FindByID(id int64) (*models.Workspace, error) {
+	workspace := &models.Workspace{}
+	query := `
+		SELECT id, name, created_by, created_at, updated_at
+		FROM workspaces
+		WHERE id = $1
+	`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&workspace.ID,
+		&workspace.Name,
+		&workspace.CreatedBy,
+		&workspace.CreatedAt,
+		&workspace.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.New("workspace not found")
+	}
+
+	return workspace, err
+}
+
+// FindByUser lists the workspaces the user belongs to along with their role.
+func (r *WorkspaceRepository) FindByUser(userID int64) ([]models.WorkspaceResponse, error) {
+	query := `
+		SELECT w.id, w.name, m.role, w.created_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = $1
+		ORDER BY w.created_at
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := []models.WorkspaceResponse{}
+	for rows.Next() {
+		var workspace models.WorkspaceResponse
+		if err := rows.Scan(&workspace.ID, &workspace.Name, &workspace.Role, &workspace.CreatedAt); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, rows.Err()
+}
+
+func (r *WorkspaceRepository) Update(workspace *models.Workspace) error {
+	query := `
+		UPDATE workspaces
+		SET name = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+
+	result, err := r.db.Exec(query, workspace.Name, workspace.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("workspace not found")
+	}
+
+	return nil
+}
+
+// Delete removes the workspace together with its links and memberships and
+// returns the short codes of the deleted links.
+func (r *WorkspaceRepository) Delete(id int64) ([]string, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`DELETE FROM short_links WHERE workspace_id = $1 RETURNING short_code`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var shortCodes []string
+	for rows.Next() {
+		var shortCode string
+		if err := rows.Scan(&shortCode); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		shortCodes = append(shortCodes, shortCode)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.Exec(`DELETE FROM workspaces WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected == 0 {
+		return nil, errors.New("workspace not found")
+	}
+
+	return shortCodes, tx.Commit()
+}
+
+func (r *WorkspaceRepository) GetMemberRole(workspaceID, userID int64) (string, error) {
+	var role string
+	query := `SELECT role FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`
+
+	err := r.db.QueryRow(query, workspaceID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", ErrNotWorkspaceMember
+	}
+
+	return role, err
+}
+
+func (r *WorkspaceRepository) FindMembers(workspaceID int64) ([]models.WorkspaceMember, error) {
+	query := `
+		SELECT m.workspace_id, m.user_id, u.full_name, u.email, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1 AND u.deleted_at IS NULL
+		ORDER BY m.created_at
+	`
+
+	rows, err := r.db.Query(query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.WorkspaceMember{}
+	for rows.Next() {
+		var member models.WorkspaceMember
+		err := rows.Scan(
+			&member.WorkspaceID,
+			&member.UserID,
+			&member.FullName,
+			&member.Email,
+			&member.Role,
+			&member.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+func (r *WorkspaceRepository) AddMember(workspaceID, userID int64, role string) error {
+	query := `
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`
+
+	result, err := r.db.Exec(query, workspaceID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("user is already a member of this workspace")
+	}
+
+	return nil
+}
+
+func (r *WorkspaceRepository) UpdateMemberRole(workspaceID, userID int64, role string) error {
+	return r.changeMembership(workspaceID, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.Exec(
+			`UPDATE workspace_members SET role = $1 WHERE workspace_id = $2 AND user_id = $3`,
+			role, workspaceID, userID,
+		)
+	})
+}
+
+func (r *WorkspaceRepository) RemoveMember(workspaceID, userID int64) error {
+	return r.changeMembership(workspaceID, func(tx *sql.Tx) (sql.Result, error) {
+		return tx.Exec(
+			`DELETE FROM workspace_members WHERE workspace_id = $1 AND user_id = $2`,
+			workspaceID, userID,
+		)
+	})
+}
+
+// CountSoleOwnerships counts the workspaces in which the user is the only
+// owner while other members remain. Members whose accounts are pending
+// deletion count neither as owners nor as remaining members.
+func (r *WorkspaceRepository) CountSoleOwnerships(userID int64) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM workspace_members m
+		WHERE m.user_id = $1 AND m.role = $2
+		  AND NOT EXISTS (
+		      SELECT 1 FROM workspace_members o
+		      JOIN users u ON u.id = o.user_id
+		      WHERE o.workspace_id = m.workspace_id AND o.user_id <> m.user_id AND o.role = $2
+		        AND u.deleted_at IS NULL
+		  )
+		  AND EXISTS (
+		      SELECT 1 FROM workspace_members o
+		      JOIN users u ON u.id = o.user_id
+		      WHERE o.workspace_id = m.workspace_id AND o.user_id <> m.user_id
+		        AND u.deleted_at IS NULL
+		  )
+	`
+
+	var count int64
+	err := r.db.QueryRow(query, userID, models.WorkspaceRoleOwner).Scan(&count)
+	return count, err
+}
+
+// changeMembership applies a membership change while holding a lock on the
+// workspace, and rolls it back if the workspace would be left without an
+// owner. Owners whose accounts are pending deletion do not count.
+func (r *WorkspaceRepository) changeMembership(workspaceID int64, change func(tx *sql.Tx) (sql.Result, error)) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM workspaces WHERE id = $1 FOR UPDATE`, workspaceID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return errors.New("workspace not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	result, err := change(tx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return errors.New("workspace member not found")
+	}
+
+	var owners int64
+	err = tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = $1 AND m.role = $2 AND u.deleted_at IS NULL
+	`, workspaceID, models.WorkspaceRoleOwner).Scan(&owners)
+	if err != nil {
+		return err
+	}
+
+	if owners == 0 {
+		return errors.New("a workspace must keep at least one owner")
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"koda-shortlink-backend/internal/utils"
+	"log"
+	"time"
+)
+
+const (
+	ExportFormatJSON = "json"
+	ExportFormatZIP  = "zip"
+)
+
+type AccountService struct {
+	userRepo            *repository.UserRepository
+	linkRepo            *repository.ShortLinkRepository
+	clickRepo           *repository.ClickRepository
+	workspaceRepo       *repository.WorkspaceRepository
+	linkService         *LinkService
+	verificationService *VerificationService
+	denylist            *utils.TokenDenylist
+	gracePeriod         time.Duration
+	baseURL             string
+}
+
+func NewAccountService(userRepo *repository.UserRepository, linkRepo *repository.ShortLinkRepository, clickRepo *repository.ClickRepository, workspaceRepo *repository.WorkspaceRepository, linkService *LinkService, verificationService *VerificationService, denylist *utils.TokenDenylist, gracePeriod time.Duration, baseURL string) *AccountService {
+	return &AccountService{
+		userRepo:            userRepo,
+		linkRepo:            linkRepo,
+		clickRepo:           clickRepo,
+		workspaceRepo:       workspaceRepo,
+		linkService:         linkService,
+		verificationService: verificationService,
+		denylist:            denylist,
+		gracePeriod:         gracePeriod,
+		baseURL:             baseURL,
+	}
+}
+
+// RequestDeletionConfirmation emails a single-use token that confirms
+// DeleteAccount in place of the password.
+func (s *AccountService) RequestDeletionConfirmation(userID int64) error {
+	return s.verificationService.SendAccountDeletionEmail(userID)
+}
+
+// DeleteAccount soft-deletes the user, signs out every session and disables
+// their links. The data is kept until PurgeDeletedAccounts runs after the
+// grace period. Accounts created through Google never saw their password, so
+// a token from RequestDeletionConfirmation is accepted instead.
+func (s *AccountService) DeleteAccount(userID int64, req *models.DeleteAccountRequest) (*models.DeleteAccountResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	soleOwnerships, err := s.workspaceRepo.CountSoleOwnerships(userID)
+	if err != nil {
+		return nil, errors.New("failed to delete account")
+	}
+	if soleOwnerships > 0 {
+		return nil, errors.New("transfer ownership of your shared workspaces before deleting your account")
+	}
+
+	if err := s.confirmDeletion(user, req); err != nil {
+		return nil, err
+	}
+
+	// Revoking first means a failure leaves the account intact and the
+	// request can simply be retried.
+	if err := s.denylist.RevokeUser(userID); err != nil {
+		return nil, errors.New("failed to revoke access tokens")
+	}
+
+	shortCodes, err := s.userRepo.SoftDelete(userID)
+	if err != nil {
+		return nil, errors.New("failed to delete account")
+	}
+
+	s.linkService.EvictDestinations(shortCodes)
+
+	return &models.DeleteAccountResponse{
+		PurgeAt: time.Now().Add(s.gracePeriod),
+	}, nil
+}
+
+func (s *AccountService) confirmDeletion(user *models.User, req *models.DeleteAccountRequest) error {
+	if req.Token != "" {
+		return s.verificationService.ConfirmAccountDeletion(req.Token, user)
+	}
+
+	if req.Password == "" {
+		return errors.New("password or confirmation token is required")
+	}
+
+	valid, err := utils.VerifyPassword(req.Password, user.Password)
+	if err != nil || !valid {
+		return errors.New("password is incorrect")
+	}
+
+	return nil
+}
+
+func (s *AccountService) PurgeDeletedAccounts() (int64, error) {
+	return s.userRepo.PurgeDeleted(time.Now().Add(-s.gracePeriod))
+}
+
+// StartPurgeScheduler purges accounts past the grace period right away and
+// then on every interval for the lifetime of the process.
+func (s *AccountService) StartPurgeScheduler(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := s.PurgeDeletedAccounts()
+			if err != nil {
+				log.Printf("Failed to purge deleted accounts: %v", err)
+			} else if purged > 0 {
+				log.Printf("Purged %d deleted accounts", purged)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
+
+// ExportData writes the user's profile, links and click history to w. The
+// JSON format is a single document; the ZIP format holds profile.json,
+// links.json and clicks.json. Clicks are streamed from the database.
+func (s *AccountService) ExportData(userID int64, format string, w io.Writer) error {
+	if format != ExportFormatJSON && format != ExportFormatZIP {
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	links, err := s.linkRepo.FindAllByUser(userID)
+	if err != nil {
+		return errors.New("failed to retrieve links")
+	}
+
+	linkResponses := make([]models.LinkResponse, len(links))
+	for i, link := range links {
+		linkResponses[i] = *link.ToResponse(s.baseURL)
+	}
+
+	if format == ExportFormatZIP {
+		return s.writeZIPExport(userID, user.ToResponse(), linkResponses, w)
+	}
+
+	return s.writeJSONExport(userID, user.ToResponse(), linkResponses, w)
+}
+
+func (s *AccountService) writeJSONExport(userID int64, profile *models.UserResponse, links []models.LinkResponse, w io.Writer) error {
+	exportedAt, _ := json.Marshal(time.Now())
+	profileJSON, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	linksJSON, err := json.Marshal(links)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, `{"exported_at":%s,"profile":%s,"links":%s,"clicks":`, exportedAt, profileJSON, linksJSON); err != nil {
+		return err
+	}
+
+	if err := s.writeClicks(userID, w); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+func (s *AccountService) writeZIPExport(userID int64, profile *models.UserResponse, links []models.LinkResponse, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"links.json", links},
+	}
+
+	for _, file := range files {
+		fw, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(fw)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	fw, err := archive.Create("clicks.json")
+	if err != nil {
+		return err
+	}
+
+	if err := s.writeClicks(userID, fw); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func (s *AccountService) writeClicks(userID int64, w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	first := true
+	err := s.clickRepo.ForEachByUser(userID, func(record *models.ClickRecord) error {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"log"
+	"math"
+)
+
+type AdminService struct {
+	userRepo    *repository.UserRepository
+	linkRepo    *repository.ShortLinkRepository
+	userService *UserService
+	linkService *LinkService
+	baseURL     string
+}
+
+func NewAdminService(userRepo *repository.UserRepository, linkRepo *repository.ShortLinkRepository, userService *UserService, linkService *LinkService, baseURL string) *AdminService {
+	return &AdminService{
+		userRepo:    userRepo,
+		linkRepo:    linkRepo,
+		userService: userService,
+		linkService: linkService,
+		baseURL:     baseURL,
+	}
+}
+
+func (s *AdminService) ListUsers(filter *models.UserFilter, page, pageSize int) (*models.AdminUserListResponse, error) {
+	if filter.Role != "" && filter.Role != models.RoleUser && filter.Role != models.RoleAdmin {
+		return nil, errors.New("invalid role")
+	}
+
+	page, pageSize = normalizePage(page, pageSize)
+
+	users, total, err := s.userRepo.Search(filter, page, pageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve users")
+	}
+
+	userResponses := make([]models.AdminUserResponse, len(users))
+	for i, user := range users {
+		userResponses[i] = *user.ToAdminResponse()
+	}
+
+	return &models.AdminUserListResponse{
+		Users:      userResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *AdminService) GetUser(userID int64) (*models.AdminUserResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return user.ToAdminResponse(), nil
+}
+
+func (s *AdminService) SetUserActive(adminID, userID int64, active bool) (*models.AdminUserResponse, error) {
+	if adminID == userID && !active {
+		return nil, errors.New("you cannot deactivate your own account")
+	}
+
+	if err := s.userService.SetActive(userID, active); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[ADMIN] User %d set is_active=%t on user %d", adminID, active, userID)
+
+	return s.GetUser(userID)
+}
+
+func (s *AdminService) ListLinks(filter *models.LinkFilter, page, pageSize int) (*models.AdminLinkListResponse, error) {
+	page, pageSize = normalizePage(page, pageSize)
+
+	links, total, err := s.linkRepo.Search(filter, page, pageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve links")
+	}
+
+	linkResponses := make([]models.AdminLinkResponse, len(links))
+	for i, link := range links {
+		linkResponses[i] = *link.ToAdminResponse(s.baseURL)
+	}
+
+	return &models.AdminLinkListResponse{
+		Links:      linkResponses,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}, nil
+}
+
+func (s *AdminService) GetLink(shortCode string) (*models.AdminLinkResponse, error) {
+	link, err := s.linkRepo.FindByShortCode(shortCode)
+	if err != nil {
+		return nil, errors.New("link not found")
+	}
+
+	return link.ToAdminResponse(s.baseURL), nil
+}
+
+func (s *AdminService) SetLinkBlocked(adminID int64, shortCode string, blocked bool) (*models.AdminLinkResponse, error) {
+	link, err := s.linkService.SetLinkBlocked(shortCode, blocked)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[ADMIN] User %d set blocked=%t on link %s", adminID, blocked, shortCode)
+
+	return link.ToAdminResponse(s.baseURL), nil
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"koda-shortlink-backend/internal/utils"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyPrefix       = "ksk_"
+	apiKeyDisplayChars = 12
+	maxAPIKeysPerUser  = 20
+)
+
+type APIKeyService struct {
+	apiKeyRepo *repository.APIKeyRepository
+	userRepo   *repository.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepo *repository.APIKeyRepository, userRepo *repository.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (s *APIKeyService) CreateAPIKey(userID int64, req *models.CreateAPIKeyRequest) (*models.APIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, errors.New("name must be 1-100 characters")
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		if !isValidScope(scope) {
+			return nil, errors.New("invalid scope: " + scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != nil && *req.ExpiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+		if err != nil {
+			return nil, errors.New("invalid expiry date format")
+		}
+		if parsed.Before(time.Now()) {
+			return nil, errors.New("expiry date must be in the future")
+		}
+		expiresAt = &parsed
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, errEmailNotVerified
+	}
+
+	count, err := s.apiKeyRepo.CountActiveByUser(userID)
+	if err != nil {
+		return nil, errors.New("failed to create api key")
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, errors.New("api key limit reached")
+	}
+
+	secret, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to generate api key")
+	}
+	rawKey := apiKeyPrefix + secret
+
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    rawKey[:apiKeyDisplayChars],
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := s.apiKeyRepo.Create(key); err != nil {
+		return nil, errors.New("failed to create api key")
+	}
+
+	resp := key.ToResponse()
+	resp.Key = rawKey
+
+	return resp, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(userID int64) ([]models.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.FindByUser(userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve api keys")
+	}
+
+	keyResponses := make([]models.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		keyResponses[i] = *key.ToResponse()
+	}
+
+	return keyResponses, nil
+}
+
+func (s *APIKeyService) RevokeAPIKey(userID, keyID int64) error {
+	if err := s.apiKeyRepo.Revoke(keyID, userID); err != nil {
+		return errors.New("api key not found")
+	}
+
+	return nil
+}
+
+func (s *APIKeyService) Authenticate(rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, errors.New("invalid api key")
+	}
+
+	key, err := s.apiKeyRepo.FindActiveByHash(utils.HashToken(rawKey))
+	if err != nil {
+		return nil, errors.New("invalid api key")
+	}
+
+	s.apiKeyRepo.TouchLastUsed(key.ID)
+
+	return key, nil
+}
+
+func isValidScope(scope string) bool {
+	for _, s := range models.APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
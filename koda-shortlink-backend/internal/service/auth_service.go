@@ -5,29 +5,42 @@ import (
 	"koda-shortlink-backend/internal/models"
 	"koda-shortlink-backend/internal/repository"
 	"koda-shortlink-backend/internal/utils"
+	"log"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// ErrRefreshFailed means a refresh could not complete for reasons unrelated
+// to the token itself, so the client may retry with the same token.
+var ErrRefreshFailed = errors.New("failed to refresh token")
+
 type AuthService struct {
-	userRepo    *repository.UserRepository
-	sessionRepo *repository.SessionRepository
-	jwtUtil     *utils.JWTUtil
+	userRepo       *repository.UserRepository
+	sessionRepo    *repository.SessionRepository
+	jwtUtil        *utils.JWTUtil
+	denylist       *utils.TokenDenylist
+	loginThrottle  *LoginThrottle
+	passwordPolicy *utils.PasswordPolicy
 }
 
-func NewAuthService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, jwtUtil *utils.JWTUtil) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, jwtUtil *utils.JWTUtil, denylist *utils.TokenDenylist, loginThrottle *LoginThrottle, passwordPolicy *utils.PasswordPolicy) *AuthService {
 	return &AuthService{
-		userRepo:    userRepo,
-		sessionRepo: sessionRepo,
-		jwtUtil:     jwtUtil,
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		jwtUtil:        jwtUtil,
+		denylist:       denylist,
+		loginThrottle:  loginThrottle,
+		passwordPolicy: passwordPolicy,
 	}
 }
 
-func (s *AuthService) Register(req *models.UserRegisterRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Register(req *models.UserRegisterRequest, client *models.ClientInfo) (*models.AuthResponse, error) {
 	if req.Password != req.ConfirmPassword {
 		return nil, errors.New("passwords do not match")
 	}
 
-	if err := utils.ValidatePassword(req.Password); err != nil {
+	if err := s.passwordPolicy.Validate(req.Password, req.Email, req.FullName); err != nil {
 		return nil, err
 	}
 
@@ -59,76 +72,74 @@ func (s *AuthService) Register(req *models.UserRegisterRequest) (*models.AuthRes
 		return nil, errors.New("failed to create user")
 	}
 
-	tokens, err := s.generateTokenPair(user.ID, user.Email)
-	if err != nil {
-		return nil, errors.New("failed to generate tokens")
-	}
-
-	session := &models.Session{
-		UserID:       user.ID,
-		RefreshToken: tokens.RefreshToken,
-		ExpiresAt:    time.Now().Add(s.jwtUtil.GetRefreshExpiry()),
-	}
-	if err := s.sessionRepo.Create(session); err != nil {
-		return nil, errors.New("failed to create session")
-	}
-
-	return &models.AuthResponse{
-		User:   user.ToResponse(),
-		Tokens: tokens,
-	}, nil
+	return s.createAuthResponse(user, client)
 }
 
-func (s *AuthService) Login(req *models.UserLoginRequest) (*models.AuthResponse, error) {
+func (s *AuthService) Login(req *models.UserLoginRequest, client *models.ClientInfo) (*models.AuthResponse, error) {
 	if !utils.IsValidEmail(req.Email) {
 		return nil, errors.New("invalid email format")
 	}
 
+	if err := s.loginThrottle.Check(req.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
+		s.loginThrottle.RecordFailure(req.Email, client.IPAddress)
 		return nil, errors.New("invalid email or password")
 	}
 
 	valid, err := utils.VerifyPassword(req.Password, user.Password)
 	if err != nil || !valid {
+		s.loginThrottle.RecordFailure(req.Email, client.IPAddress)
 		return nil, errors.New("invalid email or password")
 	}
 
+	if utils.NeedsRehash(user.Password) {
+		s.rehashPassword(user, req.Password)
+	}
+
 	if !user.IsActive {
 		return nil, errors.New("account is inactive")
 	}
 
-	tokens, err := s.generateTokenPair(user.ID, user.Email)
+	return s.completeLogin(user, client)
+}
+
+// rehashPassword upgrades a hash made with outdated Argon2 parameters while
+// the plaintext is at hand. Failures are logged and do not block the login.
+func (s *AuthService) rehashPassword(user *models.User, password string) {
+	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		return nil, errors.New("failed to generate tokens")
+		log.Printf("Failed to rehash password for user %d: %v", user.ID, err)
+		return
 	}
 
-	session := &models.Session{
-		UserID:       user.ID,
-		RefreshToken: tokens.RefreshToken,
-		ExpiresAt:    time.Now().Add(s.jwtUtil.GetRefreshExpiry()),
-	}
-	if err := s.sessionRepo.Create(session); err != nil {
-		return nil, errors.New("failed to create session")
+	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		log.Printf("Failed to store rehashed password for user %d: %v", user.ID, err)
+		return
 	}
 
-	return &models.AuthResponse{
-		User:   user.ToResponse(),
-		Tokens: tokens,
-	}, nil
+	user.Password = hashedPassword
 }
 
-func (s *AuthService) RefreshToken(refreshToken string) (*models.TokenPair, error) {
-	claims, err := s.jwtUtil.ValidateToken(refreshToken)
+func (s *AuthService) RefreshToken(refreshToken string, client *models.ClientInfo) (*models.TokenPair, error) {
+	claims, err := s.jwtUtil.ValidateToken(refreshToken, utils.TokenTypeRefresh)
 	if err != nil {
 		return nil, errors.New("invalid refresh token")
 	}
 
-	session, err := s.sessionRepo.FindByRefreshToken(refreshToken)
+	session, err := s.sessionRepo.FindByRefreshTokenHash(utils.HashToken(refreshToken))
 	if err != nil {
 		return nil, errors.New("session not found or expired")
 	}
 
+	if session.RotatedAt != nil {
+		s.revokeReusedFamily(session, client)
+		return nil, errors.New("refresh token has already been used")
+	}
+
 	user, err := s.userRepo.FindByID(session.UserID)
 	if err != nil {
 		return nil, errors.New("user not found")
@@ -138,27 +149,164 @@ func (s *AuthService) RefreshToken(refreshToken string) (*models.TokenPair, erro
 		return nil, errors.New("account is inactive")
 	}
 
+	if err := s.sessionRepo.MarkRotated(session.ID); err != nil {
+		if errors.Is(err, repository.ErrSessionRotated) {
+			s.revokeReusedFamily(session, client)
+			return nil, errors.New("refresh token has already been used")
+		}
+		log.Printf("Failed to rotate session %d: %v", session.ID, err)
+		return nil, ErrRefreshFailed
+	}
+
 	tokens, err := s.generateTokenPair(claims.UserID, claims.Email)
 	if err != nil {
-		return nil, errors.New("failed to generate tokens")
+		return nil, ErrRefreshFailed
 	}
 
-	s.sessionRepo.DeleteByRefreshToken(refreshToken)
-
 	newSession := &models.Session{
-		UserID:       session.UserID,
-		RefreshToken: tokens.RefreshToken,
-		ExpiresAt:    time.Now().Add(s.jwtUtil.GetRefreshExpiry()),
+		UserID:           session.UserID,
+		RefreshTokenHash: utils.HashToken(tokens.RefreshToken),
+		FamilyID:         session.FamilyID,
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		ExpiresAt:        time.Now().Add(s.jwtUtil.GetRefreshExpiry()),
 	}
 	if err := s.sessionRepo.Create(newSession); err != nil {
-		return nil, errors.New("failed to create new session")
+		return nil, ErrRefreshFailed
 	}
 
 	return tokens, nil
 }
 
-func (s *AuthService) Logout(refreshToken string) error {
-	return s.sessionRepo.DeleteByRefreshToken(refreshToken)
+func (s *AuthService) revokeReusedFamily(session *models.Session, client *models.ClientInfo) {
+	log.Printf("[SECURITY] Refresh token reuse detected: user_id=%d family_id=%s session_id=%d ip=%s user_agent=%q",
+		session.UserID, session.FamilyID, session.ID, client.IPAddress, client.UserAgent)
+
+	if err := s.sessionRepo.DeleteByFamily(session.FamilyID); err != nil {
+		log.Printf("Failed to revoke session family %s: %v", session.FamilyID, err)
+	}
+}
+
+func (s *AuthService) Logout(refreshToken string, accessClaims *utils.JWTClaims) error {
+	if err := s.sessionRepo.DeleteByRefreshTokenHash(utils.HashToken(refreshToken)); err != nil {
+		return err
+	}
+
+	if accessClaims != nil && accessClaims.ExpiresAt != nil {
+		if err := s.denylist.RevokeToken(accessClaims.ID, accessClaims.ExpiresAt.Time); err != nil {
+			return errors.New("failed to revoke access token")
+		}
+	}
+
+	return nil
+}
+
+func (s *AuthService) GetSessions(userID int64) ([]models.SessionResponse, error) {
+	sessions, err := s.sessionRepo.FindActiveByUser(userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve sessions")
+	}
+
+	sessionResponses := make([]models.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		deviceInfo := utils.ParseUserAgent(session.UserAgent)
+		sessionResponses[i] = models.SessionResponse{
+			ID:         session.ID,
+			DeviceType: deviceInfo.DeviceType,
+			Browser:    deviceInfo.Browser,
+			OS:         deviceInfo.OS,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+		}
+	}
+
+	return sessionResponses, nil
+}
+
+func (s *AuthService) RevokeSession(userID, sessionID int64) error {
+	if err := s.sessionRepo.DeleteByID(sessionID, userID); err != nil {
+		return errors.New("session not found")
+	}
+
+	return nil
+}
+
+func (s *AuthService) LogoutAll(userID int64) error {
+	if err := s.sessionRepo.DeleteByUser(userID); err != nil {
+		return errors.New("failed to revoke sessions")
+	}
+
+	if err := s.denylist.RevokeUser(userID); err != nil {
+		return errors.New("failed to revoke access tokens")
+	}
+
+	return nil
+}
+
+// completeLogin clears the failure count only once no second factor is
+// pending, so that TOTP failures keep accumulating across fresh challenges.
+// StartSessionCleanup deletes expired sessions right away and then on every
+// interval for the lifetime of the process. Rotated sessions are kept for
+// reuse detection until then, so without it the table grows on every refresh.
+func (s *AuthService) StartSessionCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			deleted, err := s.sessionRepo.DeleteExpiredSessions()
+			if err != nil {
+				log.Printf("Failed to delete expired sessions: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Deleted %d expired sessions", deleted)
+			}
+
+			<-ticker.C
+		}
+	}()
+}
+
+func (s *AuthService) completeLogin(user *models.User, client *models.ClientInfo) (*models.AuthResponse, error) {
+	if !user.TOTPEnabled {
+		s.loginThrottle.Reset(user.Email)
+		return s.createAuthResponse(user, client)
+	}
+
+	challengeToken, err := s.jwtUtil.GenerateToken(user.ID, user.Email, utils.TokenTypeChallenge, twoFactorChallengeExpiry)
+	if err != nil {
+		return nil, errors.New("failed to generate tokens")
+	}
+
+	return &models.AuthResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challengeToken,
+	}, nil
+}
+
+func (s *AuthService) createAuthResponse(user *models.User, client *models.ClientInfo) (*models.AuthResponse, error) {
+	tokens, err := s.generateTokenPair(user.ID, user.Email)
+	if err != nil {
+		return nil, errors.New("failed to generate tokens")
+	}
+
+	session := &models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: utils.HashToken(tokens.RefreshToken),
+		FamilyID:         uuid.NewString(),
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		ExpiresAt:        time.Now().Add(s.jwtUtil.GetRefreshExpiry()),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, errors.New("failed to create session")
+	}
+
+	return &models.AuthResponse{
+		User:   user.ToResponse(),
+		Tokens: tokens,
+	}, nil
 }
 
 func (s *AuthService) generateTokenPair(userID int64, email string) (*models.TokenPair, error) {
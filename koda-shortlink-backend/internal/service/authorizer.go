@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+)
+
+type Permission string
+
+const (
+	// PermissionView covers reading links, analytics, stats and members.
+	PermissionView Permission = "view"
+	// PermissionEdit covers creating, updating and deleting links.
+	PermissionEdit Permission = "edit"
+	// PermissionManage covers renaming or deleting a workspace and managing
+	// its members.
+	PermissionManage Permission = "manage"
+)
+
+var workspaceRolePermissions = map[string][]Permission{
+	models.WorkspaceRoleOwner:  {PermissionView, PermissionEdit, PermissionManage},
+	models.WorkspaceRoleEditor: {PermissionView, PermissionEdit},
+	models.WorkspaceRoleViewer: {PermissionView},
+}
+
+// PermissionError means the user may not perform the action, as opposed to
+// the check itself failing.
+type PermissionError struct {
+	message string
+}
+
+func (e *PermissionError) Error() string {
+	return e.message
+}
+
+type Authorizer struct {
+	workspaceRepo *repository.WorkspaceRepository
+}
+
+func NewAuthorizer(workspaceRepo *repository.WorkspaceRepository) *Authorizer {
+	return &Authorizer{workspaceRepo: workspaceRepo}
+}
+
+// Authorize is the single permission check for links and workspaces. A
+// resource that belongs to a workspace is governed by the user's member role
+// there; a personal resource only by ownership. Resources with neither owner
+// nor workspace (anonymous links) can be viewed by anyone but not changed.
+func (a *Authorizer) Authorize(userID int64, ownerID, workspaceID *int64, permission Permission) error {
+	if workspaceID != nil {
+		role, err := a.workspaceRepo.GetMemberRole(*workspaceID, userID)
+		if errors.Is(err, repository.ErrNotWorkspaceMember) {
+			return &PermissionError{"you are not a member of this workspace"}
+		}
+		if err != nil {
+			return errors.New("failed to check permissions")
+		}
+
+		for _, granted := range workspaceRolePermissions[role] {
+			if granted == permission {
+				return nil
+			}
+		}
+
+		return &PermissionError{"your workspace role does not allow this action"}
+	}
+
+	if ownerID == nil {
+		if permission == PermissionView {
+			return nil
+		}
+		return &PermissionError{"you do not have permission to modify this resource"}
+	}
+
+	if *ownerID != userID {
+		return &PermissionError{"you do not have permission to access this resource"}
+	}
+
+	return nil
+}
+
+func (a *Authorizer) AuthorizeLink(userID int64, link *models.ShortLink, permission Permission) error {
+	return a.Authorize(userID, link.UserID, link.WorkspaceID, permission)
+}
+
+func (a *Authorizer) AuthorizeWorkspace(userID, workspaceID int64, permission Permission) error {
+	return a.Authorize(userID, nil, &workspaceID, permission)
+}
+
+// sameOwner reports whether two resources belong to the same workspace or,
+// outside workspaces, to the same user. Tags and folders only apply to links
+// with the owner they share.
+func sameOwner(userID, workspaceID, otherUserID, otherWorkspaceID *int64) bool {
+	if workspaceID != nil || otherWorkspaceID != nil {
+		return workspaceID != nil && otherWorkspaceID != nil && *workspaceID == *otherWorkspaceID
+	}
+	return userID != nil && otherUserID != nil && *userID == *otherUserID
+}
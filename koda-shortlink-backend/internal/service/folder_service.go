@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxFolderNameLength matches the folders.name column.
+const maxFolderNameLength = 100
+
+type FolderService struct {
+	folderRepo *repository.FolderRepository
+	authorizer *Authorizer
+}
+
+func NewFolderService(folderRepo *repository.FolderRepository, authorizer *Authorizer) *FolderService {
+	return &FolderService{
+		folderRepo: folderRepo,
+		authorizer: authorizer,
+	}
+}
+
+// CreateFolder adds a personal folder, or a workspace folder when req names
+// a workspace the user can edit. A parent must have the same owner.
+func (s *FolderService) CreateFolder(userID int64, req *models.CreateFolderRequest) (*models.FolderResponse, error) {
+	folder := &models.Folder{UserID: &userID}
+	if req.WorkspaceID != nil {
+		if err := s.authorizer.AuthorizeWorkspace(userID, *req.WorkspaceID, PermissionEdit); err != nil {
+			return nil, err
+		}
+		folder = &models.Folder{WorkspaceID: req.WorkspaceID}
+	}
+
+	name, err := validateFolderName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	folder.Name = name
+
+	if req.ParentID != nil {
+		parent, err := s.folderRepo.FindByID(*req.ParentID)
+		if err != nil || !sameOwner(parent.UserID, parent.WorkspaceID, folder.UserID, folder.WorkspaceID) {
+			return nil, errors.New("parent folder not found")
+		}
+		folder.ParentID = &parent.ID
+	}
+
+	if err := s.folderRepo.Create(folder); err != nil {
+		return nil, errors.New("failed to create folder")
+	}
+
+	return folder.ToResponse(), nil
+}
+
+func (s *FolderService) GetFolders(userID int64, workspaceID *int64) ([]models.FolderResponse, error) {
+	var folders []models.Folder
+	var err error
+	if workspaceID != nil {
+		if err := s.authorizer.AuthorizeWorkspace(userID, *workspaceID, PermissionView); err != nil {
+			return nil, err
+		}
+		folders, err = s.folderRepo.FindByWorkspace(*workspaceID)
+	} else {
+		folders, err = s.folderRepo.FindByUser(userID)
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve folders")
+	}
+
+	folderResponses := make([]models.FolderResponse, len(folders))
+	for i, folder := range folders {
+		folderResponses[i] = *folder.ToResponse()
+	}
+
+	return folderResponses, nil
+}
+
+// UpdateFolder renames a folder and/or moves it, together with its
+// subfolders, under another folder of the same owner or to the top level.
+func (s *FolderService) UpdateFolder(folderID, userID int64, req *models.UpdateFolderRequest) (*models.FolderResponse, error) {
+	folder, err := s.findAuthorized(folderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name, err := validateFolderName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		folder.Name = name
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			folder.ParentID = nil
+		} else {
+			parent, err := s.folderRepo.FindByID(*req.ParentID)
+			if err != nil || !sameOwner(parent.UserID, parent.WorkspaceID, folder.UserID, folder.WorkspaceID) {
+				return nil, errors.New("parent folder not found")
+			}
+
+			cycle, err := s.folderRepo.IsWithin(parent.ID, folder.ID)
+			if err != nil {
+				return nil, errors.New("failed to update folder")
+			}
+			if cycle {
+				return nil, errors.New("a folder cannot be moved into itself or one of its subfolders")
+			}
+
+			folder.ParentID = &parent.ID
+		}
+	}
+
+	if err := s.folderRepo.Update(folder); err != nil {
+		return nil, errors.New("failed to update folder")
+	}
+
+	return folder.ToResponse(), nil
+}
+
+// DeleteFolder removes the folder and its subfolders. Their links are kept
+// and become unfiled.
+func (s *FolderService) DeleteFolder(folderID, userID int64) error {
+	if _, err := s.findAuthorized(folderID, userID); err != nil {
+		return err
+	}
+
+	if err := s.folderRepo.Delete(folderID); err != nil {
+		return errors.New("failed to delete folder")
+	}
+
+	return nil
+}
+
+func (s *FolderService) findAuthorized(folderID, userID int64) (*models.Folder, error) {
+	folder, err := s.folderRepo.FindByID(folderID)
+	if err != nil {
+		return nil, errors.New("folder not found")
+	}
+
+	if err := s.authorizer.Authorize(userID, folder.UserID, folder.WorkspaceID, PermissionEdit); err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func validateFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("folder name is required")
+	}
+	if utf8.RuneCountInString(name) > maxFolderNameLength {
+		return "", fmt.Errorf("folder name must be at most %d characters", maxFolderNameLength)
+	}
+	return name, nil
+}
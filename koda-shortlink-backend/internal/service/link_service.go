@@ -2,34 +2,190 @@ package service
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"koda-shortlink-backend/internal/models"
 	"koda-shortlink-backend/internal/repository"
 	"koda-shortlink-backend/internal/utils"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+var ErrLinkNotFound = errors.New("link not found")
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+const (
+	visitorKeyTTL = 30 * 24 * time.Hour
+
+	// visitorBackfillKey records that the clicks table has been replayed
+	// into the visitor HyperLogLogs. While the replay runs it expires after
+	// visitorBackfillLock, so a crashed run is retried on the next start.
+	visitorBackfillKey   = "visitors:backfilled"
+	visitorBackfillLock  = time.Hour
+	visitorBackfillBatch = 1000
+
+	// maxBulkLinks caps a single bulk creation request.
+	maxBulkLinks = 1000
+)
+
 type LinkService struct {
 	linkRepo    *repository.ShortLinkRepository
 	clickRepo   *repository.ClickRepository
+	tagRepo     *repository.TagRepository
+	folderRepo  *repository.FolderRepository
+	authorizer  *Authorizer
 	redisClient *redis.Client
 	baseURL     string
 }
 
-func NewLinkService(linkRepo *repository.ShortLinkRepository, clickRepo *repository.ClickRepository, redisClient *redis.Client, baseURL string) *LinkService {
+func NewLinkService(linkRepo *repository.ShortLinkRepository, clickRepo *repository.ClickRepository, tagRepo *repository.TagRepository, folderRepo *repository.FolderRepository, authorizer *Authorizer, redisClient *redis.Client, baseURL string) *LinkService {
 	return &LinkService{
 		linkRepo:    linkRepo,
 		clickRepo:   clickRepo,
+		tagRepo:     tagRepo,
+		folderRepo:  folderRepo,
+		authorizer:  authorizer,
 		redisClient: redisClient,
 		baseURL:     baseURL,
 	}
 }
 
 func (s *LinkService) CreateLink(req *models.CreateLinkRequest, userID *int64) (*models.LinkResponse, error) {
+	if req.WorkspaceID != nil {
+		if userID == nil {
+			return nil, errors.New("authentication is required to create workspace links")
+		}
+		if err := s.authorizer.AuthorizeWorkspace(*userID, *req.WorkspaceID, PermissionEdit); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.FolderID != nil {
+		if userID == nil {
+			return nil, errors.New("authentication is required to file links in a folder")
+		}
+		if err := s.checkFolder(*req.FolderID, userID, req.WorkspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	link, err := s.buildLink(req, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	link.FolderID = req.FolderID
+
+	if err := s.linkRepo.Create(link); err != nil {
+		return nil, errors.New("failed to create link")
+	}
+
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("link:%s:destination", link.ShortCode)
+	s.redisClient.Set(ctx, cacheKey, link.Destination, time.Hour)
+
+	return link.ToResponse(s.baseURL), nil
+}
+
+// BulkCreateLinks validates every item and inserts the valid ones in one
+// transaction. Failures are reported per row rather than failing the batch;
+// an error is only returned when the request as a whole cannot be processed.
+func (s *LinkService) BulkCreateLinks(items []models.BulkLinkItem, userID int64, workspaceID *int64) (*models.BulkCreateLinksResponse, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no links to create")
+	}
+	if len(items) > maxBulkLinks {
+		return nil, fmt.Errorf("at most %d links can be created at once", maxBulkLinks)
+	}
+
+	if workspaceID != nil {
+		if err := s.authorizer.AuthorizeWorkspace(userID, *workspaceID, PermissionEdit); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]models.BulkLinkResult, len(items))
+	links := make([]*models.ShortLink, 0, len(items))
+	rows := make([]int, 0, len(items))
+	reserved := make(map[string]bool, len(items))
+
+	for i, item := range items {
+		results[i].Row = i + 1
+
+		req := &models.CreateLinkRequest{
+			Destination: item.Destination,
+			CustomSlug:  item.CustomSlug,
+			Title:       item.Title,
+			Description: item.Description,
+			ExpiresAt:   item.ExpiresAt,
+			WorkspaceID: workspaceID,
+		}
+
+		link, err := s.buildLink(req, &userID, reserved)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+
+		reserved[link.ShortCode] = true
+		links = append(links, link)
+		rows = append(rows, i)
+	}
+
+	if len(links) > 0 {
+		rowErrors, err := s.linkRepo.CreateMany(links)
+		if err != nil {
+			return nil, errors.New("failed to create links")
+		}
+
+		ctx := context.Background()
+		pipe := s.redisClient.Pipeline()
+		for j, link := range links {
+			result := &results[rows[j]]
+			switch {
+			case errors.Is(rowErrors[j], repository.ErrShortCodeTaken):
+				result.Error = "custom slug already taken"
+			case rowErrors[j] != nil:
+				result.Error = "failed to create link"
+			default:
+				result.Success = true
+				result.Link = link.ToResponse(s.baseURL)
+				pipe.Set(ctx, fmt.Sprintf("link:%s:destination", link.ShortCode), link.Destination, time.Hour)
+			}
+		}
+		pipe.Exec(ctx)
+	}
+
+	bulkResponse := &models.BulkCreateLinksResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			bulkResponse.Created++
+		} else {
+			bulkResponse.Failed++
+		}
+	}
+
+	return bulkResponse, nil
+}
+
+// buildLink validates a creation request and resolves its short code. Codes
+// in reserved count as taken, so a batch cannot assign the same code twice
+// before any of it is inserted.
+func (s *LinkService) buildLink(req *models.CreateLinkRequest, userID *int64, reserved map[string]bool) (*models.ShortLink, error) {
+	if strings.TrimSpace(req.Destination) == "" {
+		return nil, errors.New("destination is required")
+	}
+
 	destination, err := utils.NormalizeURL(req.Destination)
 	if err != nil {
 		return nil, errors.New("invalid URL format")
@@ -43,7 +199,7 @@ func (s *LinkService) CreateLink(req *models.CreateLinkRequest, userID *int64) (
 		shortCode = utils.SanitizeCustomSlug(*req.CustomSlug)
 
 		exists, _ := s.linkRepo.ShortCodeExists(shortCode)
-		if exists {
+		if exists || reserved[shortCode] {
 			return nil, errors.New("custom slug already taken")
 		}
 	} else {
@@ -54,7 +210,7 @@ func (s *LinkService) CreateLink(req *models.CreateLinkRequest, userID *int64) (
 			}
 
 			exists, _ := s.linkRepo.ShortCodeExists(code)
-			if !exists {
+			if !exists && !reserved[code] {
 				shortCode = code
 				break
 			}
@@ -73,41 +229,89 @@ func (s *LinkService) CreateLink(req *models.CreateLinkRequest, userID *int64) (
 		expiresAt = &parsed
 	}
 
-	link := &models.ShortLink{
+	return &models.ShortLink{
 		ShortCode:   shortCode,
 		Destination: destination,
 		UserID:      userID,
+		WorkspaceID: req.WorkspaceID,
 		Title:       req.Title,
 		Description: req.Description,
 		IsActive:    true,
 		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (s *LinkService) GetLinkByShortCode(shortCode string, userID int64) (*models.LinkResponse, error) {
+	link, err := s.linkRepo.FindByShortCode(shortCode)
+	if err != nil {
+		return nil, ErrLinkNotFound
 	}
 
-	if err := s.linkRepo.Create(link); err != nil {
-		return nil, errors.New("failed to create link")
+	if err := s.authorizer.AuthorizeLink(userID, link, PermissionView); err != nil {
+		return nil, err
 	}
 
-	ctx := context.Background()
-	cacheKey := fmt.Sprintf("link:%s:destination", shortCode)
-	s.redisClient.Set(ctx, cacheKey, destination, time.Hour)
+	linkResponses := []models.LinkResponse{*link.ToResponse(s.baseURL)}
+	if err := s.attachTags(linkResponses); err != nil {
+		return nil, errors.New("failed to retrieve link")
+	}
 
-	return link.ToResponse(s.baseURL), nil
+	return &linkResponses[0], nil
 }
 
-func (s *LinkService) GetLinkByShortCode(shortCode string, userID int64) (*models.LinkResponse, error) {
+func (s *LinkService) GetLinkAnalytics(shortCode string, userID int64, startDate, endDate string) (*models.ClickAnalytics, error) {
 	link, err := s.linkRepo.FindByShortCode(shortCode)
 	if err != nil {
-		return nil, errors.New("link not found")
+		return nil, ErrLinkNotFound
 	}
 
-	if link.UserID != nil && *link.UserID != userID {
-		return nil, errors.New("unauthorized access to this link")
+	if err := s.authorizer.AuthorizeLink(userID, link, PermissionView); err != nil {
+		return nil, err
 	}
 
-	return link.ToResponse(s.baseURL), nil
+	from, to, err := parseDateRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics, err := s.clickRepo.GetAnalytics(link.ID, from, to, 10)
+	if err != nil {
+		return nil, errors.New("failed to retrieve analytics")
+	}
+
+	return analytics, nil
+}
+
+func (s *LinkService) GetUserLinks(userID int64, query *models.LinkListQuery, page, pageSize int) (*models.LinkListResponse, error) {
+	normalizeLinkListQuery(query)
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	links, total, err := s.linkRepo.FindByUser(userID, query, page, pageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve links")
+	}
+
+	linkList, err := s.linkListResponse(links, total, page, pageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve links")
+	}
+
+	return linkList, nil
 }
 
-func (s *LinkService) GetUserLinks(userID int64, page, pageSize int) (*models.LinkListResponse, error) {
+func (s *LinkService) GetWorkspaceLinks(workspaceID, userID int64, query *models.LinkListQuery, page, pageSize int) (*models.LinkListResponse, error) {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	normalizeLinkListQuery(query)
+
 	if page < 1 {
 		page = 1
 	}
@@ -115,7 +319,49 @@ func (s *LinkService) GetUserLinks(userID int64, page, pageSize int) (*models.Li
 		pageSize = 10
 	}
 
-	links, total, err := s.linkRepo.FindByUser(userID, page, pageSize)
+	links, total, err := s.linkRepo.FindByWorkspace(workspaceID, query, page, pageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve links")
+	}
+
+	linkList, err := s.linkListResponse(links, total, page, pageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve links")
+	}
+
+	return linkList, nil
+}
+
+// GetUserLinksAfter is the cursor-paginated form of GetUserLinks. A cursor
+// carries the sort it was issued for, which takes precedence over query's.
+func (s *LinkService) GetUserLinksAfter(userID int64, query *models.LinkListQuery, cursor *models.Cursor, pageSize int) (*models.LinkCursorListResponse, error) {
+	return s.linkCursorPage(query, cursor, pageSize, func(limit int) ([]models.ShortLink, bool, error) {
+		return s.linkRepo.FindByUserAfter(userID, query, cursor, limit)
+	})
+}
+
+func (s *LinkService) GetWorkspaceLinksAfter(workspaceID, userID int64, query *models.LinkListQuery, cursor *models.Cursor, pageSize int) (*models.LinkCursorListResponse, error) {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	return s.linkCursorPage(query, cursor, pageSize, func(limit int) ([]models.ShortLink, bool, error) {
+		return s.linkRepo.FindByWorkspaceAfter(workspaceID, query, cursor, limit)
+	})
+}
+
+func (s *LinkService) linkCursorPage(query *models.LinkListQuery, cursor *models.Cursor, pageSize int, find func(limit int) ([]models.ShortLink, bool, error)) (*models.LinkCursorListResponse, error) {
+	if cursor != nil {
+		query.SortBy = cursor.Sort
+		query.SortOrder = cursor.Order
+	}
+	normalizeLinkListQuery(query)
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	links, hasMore, err := find(pageSize)
 	if err != nil {
 		return nil, errors.New("failed to retrieve links")
 	}
@@ -125,6 +371,94 @@ func (s *LinkService) GetUserLinks(userID int64, page, pageSize int) (*models.Li
 		linkResponses[i] = *link.ToResponse(s.baseURL)
 	}
 
+	if err := s.attachTags(linkResponses); err != nil {
+		return nil, errors.New("failed to retrieve links")
+	}
+
+	result := &models.LinkCursorListResponse{
+		Links:    linkResponses,
+		PageSize: pageSize,
+	}
+
+	if len(links) > 0 {
+		first, last := links[0], links[len(links)-1]
+		result.NextCursor, result.PrevCursor = pageCursors(cursor, hasMore,
+			models.Cursor{Value: first.SortValue(query.SortBy), ID: first.ID, Sort: query.SortBy, Order: query.SortOrder},
+			models.Cursor{Value: last.SortValue(query.SortBy), ID: last.ID, Sort: query.SortBy, Order: query.SortOrder},
+		)
+	}
+
+	return result, nil
+}
+
+// GetLinkClicks lists the raw clicks of a link, newest first, a page at a
+// time.
+func (s *LinkService) GetLinkClicks(shortCode string, userID int64, cursor *models.Cursor, pageSize int) (*models.ClickListResponse, error) {
+	link, err := s.linkRepo.FindByShortCode(shortCode)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+
+	if err := s.authorizer.AuthorizeLink(userID, link, PermissionView); err != nil {
+		return nil, err
+	}
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	clicks, hasMore, err := s.clickRepo.FindByLinkAfter(link.ID, cursor, pageSize)
+	if err != nil {
+		return nil, errors.New("failed to retrieve clicks")
+	}
+
+	result := &models.ClickListResponse{
+		Clicks:   clicks,
+		PageSize: pageSize,
+	}
+
+	if len(clicks) > 0 {
+		first, last := clicks[0], clicks[len(clicks)-1]
+		result.NextCursor, result.PrevCursor = pageCursors(cursor, hasMore,
+			models.Cursor{Value: first.ClickedAt.Format(models.CursorTimeLayout), ID: first.ID},
+			models.Cursor{Value: last.ClickedAt.Format(models.CursorTimeLayout), ID: last.ID},
+		)
+	}
+
+	return result, nil
+}
+
+// pageCursors derives the encoded next and previous cursors of a non-empty
+// keyset page from the cursor that requested it, whether more rows lie in
+// the direction it was read, and cursors for its first and last rows.
+func pageCursors(cursor *models.Cursor, hasMore bool, first, last models.Cursor) (string, string) {
+	hasNext, hasPrev := hasMore, cursor != nil
+	if cursor != nil && cursor.Backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	var next, prev string
+	if hasNext {
+		next = last.Encode()
+	}
+	if hasPrev {
+		first.Backward = true
+		prev = first.Encode()
+	}
+
+	return next, prev
+}
+
+func (s *LinkService) linkListResponse(links []models.ShortLink, total int64, page, pageSize int) (*models.LinkListResponse, error) {
+	linkResponses := make([]models.LinkResponse, len(links))
+	for i, link := range links {
+		linkResponses[i] = *link.ToResponse(s.baseURL)
+	}
+
+	if err := s.attachTags(linkResponses); err != nil {
+		return nil, err
+	}
+
 	totalPages := int(math.Ceil(float64(total) / float64(pageSize)))
 
 	return &models.LinkListResponse{
@@ -136,14 +470,62 @@ func (s *LinkService) GetUserLinks(userID int64, page, pageSize int) (*models.Li
 	}, nil
 }
 
+// attachTags loads the tags of all the links in one query.
+func (s *LinkService) attachTags(links []models.LinkResponse) error {
+	if len(links) == 0 {
+		return nil
+	}
+
+	linkIDs := make([]int64, len(links))
+	for i, link := range links {
+		linkIDs[i] = link.ID
+	}
+
+	tags, err := s.tagRepo.FindByLinks(linkIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range links {
+		for _, tag := range tags[links[i].ID] {
+			links[i].Tags = append(links[i].Tags, *tag.ToResponse())
+		}
+	}
+
+	return nil
+}
+
+// checkFolder makes sure a folder exists and has the given owner, which is
+// the owner of the link being filed in it.
+func (s *LinkService) checkFolder(folderID int64, userID, workspaceID *int64) error {
+	folder, err := s.folderRepo.FindByID(folderID)
+	if err != nil || !sameOwner(folder.UserID, folder.WorkspaceID, userID, workspaceID) {
+		return errors.New("folder not found")
+	}
+
+	return nil
+}
+
+// normalizeLinkListQuery trims the search term and fills in the default
+// ordering, newest first.
+func normalizeLinkListQuery(query *models.LinkListQuery) {
+	query.Search = strings.TrimSpace(query.Search)
+	if query.SortBy == "" {
+		query.SortBy = models.LinkSortCreatedAt
+	}
+	if query.SortOrder == "" {
+		query.SortOrder = models.SortOrderDesc
+	}
+}
+
 func (s *LinkService) UpdateLink(shortCode string, userID int64, req *models.UpdateLinkRequest) error {
 	link, err := s.linkRepo.FindByShortCode(shortCode)
 	if err != nil {
-		return errors.New("link not found")
+		return ErrLinkNotFound
 	}
 
-	if link.UserID == nil || *link.UserID != userID {
-		return errors.New("unauthorized to update this link")
+	if err := s.authorizer.AuthorizeLink(userID, link, PermissionEdit); err != nil {
+		return err
 	}
 
 	if req.Destination != nil {
@@ -178,6 +560,17 @@ func (s *LinkService) UpdateLink(shortCode string, userID int64, req *models.Upd
 		link.ExpiresAt = &parsed
 	}
 
+	if req.FolderID != nil {
+		if *req.FolderID == 0 {
+			link.FolderID = nil
+		} else {
+			if err := s.checkFolder(*req.FolderID, link.UserID, link.WorkspaceID); err != nil {
+				return err
+			}
+			link.FolderID = req.FolderID
+		}
+	}
+
 	if err := s.linkRepo.Update(link); err != nil {
 		return errors.New("failed to update link")
 	}
@@ -188,14 +581,14 @@ func (s *LinkService) UpdateLink(shortCode string, userID int64, req *models.Upd
 func (s *LinkService) DeleteLink(shortCode string, userID int64) error {
 	link, err := s.linkRepo.FindByShortCode(shortCode)
 	if err != nil {
-		return errors.New("link not found")
+		return ErrLinkNotFound
 	}
 
-	if link.UserID == nil || *link.UserID != userID {
-		return errors.New("unauthorized to delete this link")
+	if err := s.authorizer.AuthorizeLink(userID, link, PermissionEdit); err != nil {
+		return err
 	}
 
-	if err := s.linkRepo.Delete(link.ID, userID); err != nil {
+	if err := s.linkRepo.Delete(link.ID); err != nil {
 		return errors.New("failed to delete link")
 	}
 
@@ -206,6 +599,41 @@ func (s *LinkService) DeleteLink(shortCode string, userID int64) error {
 	return nil
 }
 
+func (s *LinkService) SetLinkBlocked(shortCode string, blocked bool) (*models.ShortLink, error) {
+	link, err := s.linkRepo.FindByShortCode(shortCode)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+
+	if err := s.linkRepo.SetBlocked(link.ID, blocked); err != nil {
+		return nil, errors.New("failed to update link")
+	}
+
+	link, err = s.linkRepo.FindByID(link.ID)
+	if err != nil {
+		return nil, ErrLinkNotFound
+	}
+
+	ctx := context.Background()
+	cacheKey := fmt.Sprintf("link:%s:destination", shortCode)
+	s.redisClient.Del(ctx, cacheKey)
+
+	return link, nil
+}
+
+func (s *LinkService) EvictDestinations(shortCodes []string) {
+	if len(shortCodes) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	cacheKeys := make([]string, len(shortCodes))
+	for i, shortCode := range shortCodes {
+		cacheKeys[i] = fmt.Sprintf("link:%s:destination", shortCode)
+	}
+	s.redisClient.Del(ctx, cacheKeys...)
+}
+
 func (s *LinkService) GetDestination(shortCode string) (string, error) {
 	ctx := context.Background()
 	cacheKey := fmt.Sprintf("link:%s:destination", shortCode)
@@ -217,13 +645,17 @@ func (s *LinkService) GetDestination(shortCode string) (string, error) {
 
 	link, err := s.linkRepo.FindByShortCode(shortCode)
 	if err != nil {
-		return "", errors.New("link not found")
+		return "", ErrLinkNotFound
 	}
 
 	if !link.IsActive {
 		return "", errors.New("link is inactive")
 	}
 
+	if link.BlockedAt != nil {
+		return "", errors.New("link has been disabled by an administrator")
+	}
+
 	if link.ExpiresAt != nil && link.ExpiresAt.Before(time.Now()) {
 		return "", errors.New("link has expired")
 	}
@@ -251,14 +683,317 @@ func (s *LinkService) RecordClick(shortCode string, click *models.Click) error {
 	counterKey := fmt.Sprintf("link:%s:clicks", shortCode)
 	s.redisClient.Incr(ctx, counterKey)
 
+	fingerprint := utils.VisitorFingerprint(click.IPAddress, click.UserAgent)
+	if ownerKey := dashboardVisitorsKey(link.UserID, link.WorkspaceID); ownerKey != "" {
+		// Day keys are in UTC to line up with DATE(clicked_at), which the
+		// database evaluates in its UTC session time zone.
+		dailyKey := fmt.Sprintf("%s:%s", ownerKey, time.Now().UTC().Format("2006-01-02"))
+
+		pipe := s.redisClient.Pipeline()
+		pipe.PFAdd(ctx, ownerKey, fingerprint)
+		pipe.PFAdd(ctx, dailyKey, fingerprint)
+		pipe.Expire(ctx, dailyKey, visitorKeyTTL)
+		pipe.Exec(ctx)
+	}
+
 	return nil
 }
 
+// BackfillUniqueVisitors replays the clicks table into the visitor
+// HyperLogLogs, so that UniqueVisitors covers the same history as
+// TotalVisits on data recorded before RecordClick kept them. It runs once per
+// Redis database; clicks recorded meanwhile are counted once either way.
+func (s *LinkService) BackfillUniqueVisitors() error {
+	ctx := context.Background()
+	claimed, err := s.redisClient.SetNX(ctx, visitorBackfillKey, time.Now().Unix(), visitorBackfillLock).Result()
+	if err != nil || !claimed {
+		return err
+	}
+
+	dailySince := time.Now().UTC().Add(-visitorKeyTTL)
+	pipe := s.redisClient.Pipeline()
+	err = s.clickRepo.ForEachVisitor(func(visitor *models.ClickVisitor) error {
+		ownerKey := dashboardVisitorsKey(visitor.UserID, visitor.WorkspaceID)
+		if ownerKey == "" {
+			return nil
+		}
+
+		fingerprint := utils.VisitorFingerprint(visitor.IPAddress, visitor.UserAgent)
+		pipe.PFAdd(ctx, ownerKey, fingerprint)
+		if clickedAt := visitor.ClickedAt.UTC(); clickedAt.After(dailySince) {
+			dailyKey := fmt.Sprintf("%s:%s", ownerKey, clickedAt.Format("2006-01-02"))
+			pipe.PFAdd(ctx, dailyKey, fingerprint)
+			pipe.Expire(ctx, dailyKey, visitorKeyTTL)
+		}
+
+		if pipe.Len() < visitorBackfillBatch {
+			return nil
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err == nil && pipe.Len() > 0 {
+		_, err = pipe.Exec(ctx)
+	}
+
+	if err != nil {
+		s.redisClient.Del(ctx, visitorBackfillKey)
+		return err
+	}
+
+	return s.redisClient.Persist(ctx, visitorBackfillKey).Err()
+}
+
 func (s *LinkService) GetDashboardStats(userID int64) (*models.DashboardStats, error) {
 	stats, err := s.linkRepo.GetDashboardStats(userID)
 	if err != nil {
 		return nil, errors.New("failed to retrieve statistics")
 	}
 
+	s.fillUniqueVisitors(stats, dashboardVisitorsKey(&userID, nil))
+
 	return stats, nil
 }
+
+func (s *LinkService) GetWorkspaceDashboardStats(workspaceID, userID int64) (*models.DashboardStats, error) {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.linkRepo.GetWorkspaceDashboardStats(workspaceID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve statistics")
+	}
+
+	s.fillUniqueVisitors(stats, dashboardVisitorsKey(nil, &workspaceID))
+
+	return stats, nil
+}
+
+func (s *LinkService) fillUniqueVisitors(stats *models.DashboardStats, ownerKey string) {
+	ctx := context.Background()
+
+	// The undated key counts every visitor since the first click, so it
+	// covers the same all-time window as TotalVisits.
+	if unique, err := s.redisClient.PFCount(ctx, ownerKey).Result(); err == nil {
+		stats.UniqueVisitors = unique
+	}
+
+	for i := range stats.Last7DaysVisits {
+		dailyKey := fmt.Sprintf("%s:%s", ownerKey, stats.Last7DaysVisits[i].Date)
+		if unique, err := s.redisClient.PFCount(ctx, dailyKey).Result(); err == nil {
+			stats.Last7DaysVisits[i].UniqueVisitors = unique
+		}
+	}
+}
+
+// ExportLinks streams the user's personal links, or those of a workspace
+// they can view, to w as CSV or NDJSON, one row per link as it is read.
+func (s *LinkService) ExportLinks(userID int64, workspaceID *int64, format string, w io.Writer) error {
+	if workspaceID != nil {
+		if err := s.authorizer.AuthorizeWorkspace(userID, *workspaceID, PermissionView); err != nil {
+			return err
+		}
+	}
+
+	out, err := newExportWriter(format, w, []string{
+		"short_code", "short_url", "destination", "title", "description",
+		"is_active", "click_count", "created_at", "expires_at",
+	})
+	if err != nil {
+		return err
+	}
+
+	write := func(link *models.ShortLink) error {
+		resp := link.ToResponse(s.baseURL)
+		return out.write(resp, []string{
+			resp.ShortCode,
+			resp.ShortURL,
+			resp.Destination,
+			stringValue(resp.Title),
+			stringValue(resp.Description),
+			strconv.FormatBool(resp.IsActive),
+			strconv.FormatInt(resp.ClickCount, 10),
+			resp.CreatedAt.Format(time.RFC3339),
+			timeValue(resp.ExpiresAt),
+		})
+	}
+
+	if workspaceID != nil {
+		err = s.linkRepo.ForEachByWorkspace(*workspaceID, write)
+	} else {
+		err = s.linkRepo.ForEachByUser(userID, write)
+	}
+	if err != nil {
+		return err
+	}
+
+	return out.flush()
+}
+
+// ExportClicks streams the raw click log of the user's personal links, or
+// of a workspace they can view, for a date range. The range follows the
+// analytics endpoint: YYYY-MM-DD dates, inclusive, defaulting to 30 days.
+func (s *LinkService) ExportClicks(userID int64, workspaceID *int64, startDate, endDate, format string, w io.Writer) error {
+	if workspaceID != nil {
+		if err := s.authorizer.AuthorizeWorkspace(userID, *workspaceID, PermissionView); err != nil {
+			return err
+		}
+	}
+
+	from, to, err := parseDateRange(startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	out, err := newExportWriter(format, w, []string{
+		"short_code", "clicked_at", "ip_address", "user_agent", "referer",
+		"country", "city", "device_type", "browser", "os",
+	})
+	if err != nil {
+		return err
+	}
+
+	write := func(record *models.ClickRecord) error {
+		return out.write(record, []string{
+			record.ShortCode,
+			record.ClickedAt.Format(time.RFC3339),
+			record.IPAddress,
+			record.UserAgent,
+			stringValue(record.Referer),
+			stringValue(record.Country),
+			stringValue(record.City),
+			stringValue(record.DeviceType),
+			stringValue(record.Browser),
+			stringValue(record.OS),
+		})
+	}
+
+	if workspaceID != nil {
+		err = s.clickRepo.ForEachByWorkspaceBetween(*workspaceID, from, to, write)
+	} else {
+		err = s.clickRepo.ForEachByUserBetween(userID, from, to, write)
+	}
+	if err != nil {
+		return err
+	}
+
+	return out.flush()
+}
+
+// DateRangeError means the requested dates are malformed or out of bounds.
+type DateRangeError struct {
+	message string
+}
+
+func (e *DateRangeError) Error() string {
+	return e.message
+}
+
+// parseDateRange turns inclusive YYYY-MM-DD dates into a half-open [from, to)
+// UTC interval. The end defaults to today and the start to 30 days before it.
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if endDate != "" {
+		parsed, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, &DateRangeError{"invalid end date format, expected YYYY-MM-DD"}
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if startDate != "" {
+		parsed, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, &DateRangeError{"invalid start date format, expected YYYY-MM-DD"}
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, &DateRangeError{"start date must not be after end date"}
+	}
+
+	if to.Sub(from) > 366*24*time.Hour {
+		return time.Time{}, time.Time{}, &DateRangeError{"date range must not exceed one year"}
+	}
+
+	return from, to, nil
+}
+
+// exportWriter writes export rows either as CSV, from the row's cells, or
+// as NDJSON, from the record itself.
+type exportWriter struct {
+	csv     *csv.Writer
+	encoder *json.Encoder
+}
+
+func newExportWriter(format string, w io.Writer, header []string) (*exportWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		out := &exportWriter{csv: csv.NewWriter(w)}
+		return out, out.csv.Write(header)
+	case ExportFormatNDJSON:
+		return &exportWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func (e *exportWriter) write(record interface{}, row []string) error {
+	if e.csv == nil {
+		return e.encoder.Encode(record)
+	}
+
+	for i, cell := range row {
+		row[i] = escapeCSVFormula(cell)
+	}
+	return e.csv.Write(row)
+}
+
+func (e *exportWriter) flush() error {
+	if e.csv == nil {
+		return nil
+	}
+
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// escapeCSVFormula prefixes cells that spreadsheets would evaluate as a
+// formula, since titles, referers and user agents are attacker controlled.
+func escapeCSVFormula(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func timeValue(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+// dashboardVisitorsKey is the all-time visitor HyperLogLog that feeds a
+// dashboard, and the prefix of its daily ones: the workspace's for workspace
+// links, otherwise the owner's.
+func dashboardVisitorsKey(userID, workspaceID *int64) string {
+	if workspaceID != nil {
+		return fmt.Sprintf("workspace:%d:visitors", *workspaceID)
+	}
+	if userID != nil {
+		return fmt.Sprintf("user:%d:visitors", *userID)
+	}
+	return ""
+}
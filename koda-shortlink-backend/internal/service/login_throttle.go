@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailureWindow = 15 * time.Minute
+	loginLockoutPeriod = 15 * time.Minute
+	maxLoginDelay      = 30 * time.Second
+
+	// Failures tolerated before delays start, and the count at which the
+	// key is locked out for loginLockoutPeriod. IPs get more headroom since
+	// several users may share one address.
+	accountFreeAttempts = 3
+	accountLockoutAt    = 10
+	ipFreeAttempts      = 10
+	ipLockoutAt         = 50
+)
+
+// LoginLockedError is returned while an account or IP address has to wait
+// before another login attempt is accepted.
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", seconds)
+}
+
+type LoginThrottle struct {
+	redisClient *redis.Client
+}
+
+func NewLoginThrottle(redisClient *redis.Client) *LoginThrottle {
+	return &LoginThrottle{redisClient: redisClient}
+}
+
+// Check fails open when Redis is unavailable, like the rate limiter, so an
+// outage does not lock every user out.
+func (t *LoginThrottle) Check(email, ip string) error {
+	ctx := context.Background()
+
+	pipe := t.redisClient.Pipeline()
+	accountTTL := pipe.PTTL(ctx, accountLockKey(email))
+	ipTTL := pipe.PTTL(ctx, ipLockKey(ip))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		log.Printf("Failed to check login throttle: %v", err)
+		return nil
+	}
+
+	wait := max(accountTTL.Val(), ipTTL.Val())
+	if wait > 0 {
+		return &LoginLockedError{RetryAfter: wait}
+	}
+
+	return nil
+}
+
+func (t *LoginThrottle) RecordFailure(email, ip string) {
+	ctx := context.Background()
+
+	pipe := t.redisClient.Pipeline()
+	accountFailures := pipe.Incr(ctx, accountFailureKey(email))
+	pipe.Expire(ctx, accountFailureKey(email), loginFailureWindow)
+	ipFailures := pipe.Incr(ctx, ipFailureKey(ip))
+	pipe.Expire(ctx, ipFailureKey(ip), loginFailureWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+		return
+	}
+
+	pipe = t.redisClient.Pipeline()
+	if d := lockDuration(accountFailures.Val(), accountFreeAttempts, accountLockoutAt); d > 0 {
+		pipe.Set(ctx, accountLockKey(email), 1, d)
+	}
+	if d := lockDuration(ipFailures.Val(), ipFreeAttempts, ipLockoutAt); d > 0 {
+		pipe.Set(ctx, ipLockKey(ip), 1, d)
+	}
+	if accountFailures.Val() == accountLockoutAt {
+		log.Printf("[SECURITY] Account %s locked after %d failed login attempts from %s", normalizeEmail(email), accountFailures.Val(), ip)
+	}
+	if ipFailures.Val() == ipLockoutAt {
+		log.Printf("[SECURITY] IP %s locked after %d failed login attempts", ip, ipFailures.Val())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to apply login lockout: %v", err)
+	}
+}
+
+// Reset clears the failure count and lock of an account. The per-IP state is
+// left alone so that signing into one account does not reset the budget for
+// guessing others.
+func (t *LoginThrottle) Reset(email string) {
+	ctx := context.Background()
+	if err := t.redisClient.Del(ctx, accountFailureKey(email), accountLockKey(email)).Err(); err != nil {
+		log.Printf("Failed to reset login throttle: %v", err)
+	}
+}
+
+// lockDuration doubles the delay with every failure past the free attempts,
+// starting at one second, and switches to the full lockout at lockoutAt.
+func lockDuration(failures int64, freeAttempts, lockoutAt int64) time.Duration {
+	if failures < freeAttempts {
+		return 0
+	}
+	if failures >= lockoutAt {
+		return loginLockoutPeriod
+	}
+
+	delay := time.Second << (failures - freeAttempts)
+	return min(delay, maxLoginDelay)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func accountFailureKey(email string) string {
+	return fmt.Sprintf("login:failures:account:%s", normalizeEmail(email))
+}
+
+func accountLockKey(email string) string {
+	return fmt.Sprintf("login:lock:account:%s", normalizeEmail(email))
+}
+
+func ipFailureKey(ip string) string {
+	return fmt.Sprintf("login:failures:ip:%s", ip)
+}
+
+func ipLockKey(ip string) string {
+	return fmt.Sprintf("login:lock:ip:%s", ip)
+}
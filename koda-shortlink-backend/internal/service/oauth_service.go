@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"koda-shortlink-backend/internal/config"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"koda-shortlink-backend/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/oauth2"
+)
+
+const (
+	googleProvider = "google"
+
+	// OAuthStateTTL bounds both the stored PKCE verifier and the browser
+	// cookie that binds the state to the client that started the login.
+	OAuthStateTTL = 10 * time.Minute
+
+	// oauthRequestTimeout caps the code exchange and the user info request.
+	oauthRequestTimeout = 10 * time.Second
+)
+
+type OAuthService struct {
+	authService  *AuthService
+	userRepo     *repository.UserRepository
+	oauthRepo    *repository.OAuthAccountRepository
+	redisClient  *redis.Client
+	googleConfig *oauth2.Config
+	userInfoURL  string
+}
+
+type googleUserInfo struct {
+	Sub           string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+func NewOAuthService(authService *AuthService, userRepo *repository.UserRepository, oauthRepo *repository.OAuthAccountRepository, redisClient *redis.Client, cfg *config.OAuthConfig) *OAuthService {
+	return &OAuthService{
+		authService: authService,
+		userRepo:    userRepo,
+		oauthRepo:   oauthRepo,
+		redisClient: redisClient,
+		googleConfig: &oauth2.Config{
+			ClientID:     cfg.GoogleClientID,
+			ClientSecret: cfg.GoogleClientSecret,
+			RedirectURL:  cfg.GoogleRedirectURL,
+			Scopes:       []string{"openid", "email", "profile"},
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  cfg.GoogleAuthURL,
+				TokenURL: cfg.GoogleTokenURL,
+			},
+		},
+		userInfoURL: cfg.GoogleUserInfoURL,
+	}
+}
+
+// GoogleAuthURL returns the consent screen URL along with its state, which
+// the caller must hand to the browser so GoogleCallback can check it.
+func (s *OAuthService) GoogleAuthURL() (string, string, error) {
+	if s.googleConfig.ClientID == "" {
+		return "", "", errors.New("google login is not configured")
+	}
+
+	state, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return "", "", errors.New("failed to generate state")
+	}
+
+	verifier := oauth2.GenerateVerifier()
+
+	ctx := context.Background()
+	stateKey := fmt.Sprintf("oauth:state:%s", state)
+	if err := s.redisClient.Set(ctx, stateKey, verifier, OAuthStateTTL).Err(); err != nil {
+		return "", "", errors.New("failed to store state")
+	}
+
+	return s.googleConfig.AuthCodeURL(state, oauth2.AccessTypeOnline, oauth2.S256ChallengeOption(verifier)), state, nil
+}
+
+// GoogleCallback finishes the login. browserState is the state the browser
+// kept from GoogleAuthURL; it must match the one Google echoes back, so a
+// callback link crafted by someone else cannot sign the victim in.
+func (s *OAuthService) GoogleCallback(code, state, browserState string, client *models.ClientInfo) (*models.AuthResponse, error) {
+	if code == "" || state == "" {
+		return nil, errors.New("missing code or state")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, errors.New("state does not match this browser")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oauthRequestTimeout)
+	defer cancel()
+
+	stateKey := fmt.Sprintf("oauth:state:%s", state)
+	verifier, err := s.redisClient.GetDel(ctx, stateKey).Result()
+	if err != nil {
+		return nil, errors.New("invalid or expired state")
+	}
+
+	token, err := s.googleConfig.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, errors.New("failed to exchange authorization code")
+	}
+
+	info, err := s.fetchGoogleUserInfo(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(info)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is inactive")
+	}
+
+	return s.authService.completeLogin(user, client)
+}
+
+func (s *OAuthService) fetchGoogleUserInfo(ctx context.Context, token *oauth2.Token) (*googleUserInfo, error) {
+	client := s.googleConfig.Client(ctx, token)
+
+	resp, err := client.Get(s.userInfoURL)
+	if err != nil {
+		return nil, errors.New("failed to fetch user info")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user info request failed with status %d", resp.StatusCode)
+	}
+
+	info := &googleUserInfo{}
+	if err := json.NewDecoder(resp.Body).Decode(info); err != nil {
+		return nil, errors.New("invalid user info response")
+	}
+
+	if info.Sub == "" || info.Email == "" {
+		return nil, errors.New("incomplete user info response")
+	}
+
+	return info, nil
+}
+
+func (s *OAuthService) resolveUser(info *googleUserInfo) (*models.User, error) {
+	account, err := s.oauthRepo.FindByProvider(googleProvider, info.Sub)
+	if err == nil {
+		user, err := s.userRepo.FindByID(account.UserID)
+		if err != nil {
+			return nil, errors.New("user not found")
+		}
+		return user, nil
+	}
+
+	// Linking an unverified account would let whoever registered the address
+	// first keep a working password on it. Its owner has to claim it through a
+	// password reset, which verifies the email and revokes other sessions.
+	user, err := s.userRepo.FindByEmail(info.Email)
+	if err == nil {
+		if !info.EmailVerified {
+			return nil, errors.New("email already registered")
+		}
+		if user.EmailVerifiedAt == nil {
+			return nil, errors.New("email already registered to an unverified account, reset its password to claim it")
+		}
+	} else {
+		user, err = s.createOAuthUser(info)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	account = &models.OAuthAccount{
+		UserID:     user.ID,
+		Provider:   googleProvider,
+		ProviderID: info.Sub,
+		Email:      info.Email,
+	}
+	if err := s.oauthRepo.Create(account); err != nil {
+		return nil, errors.New("failed to link oauth account")
+	}
+
+	return user, nil
+}
+
+func (s *OAuthService) createOAuthUser(info *googleUserInfo) (*models.User, error) {
+	if !info.EmailVerified {
+		return nil, errors.New("google account email is not verified")
+	}
+
+	randomPassword, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to generate password")
+	}
+
+	hashedPassword, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	fullName := info.Name
+	if fullName == "" {
+		fullName = info.Email
+	}
+
+	verifiedAt := time.Now()
+	user := &models.User{
+		FullName:        fullName,
+		Email:           info.Email,
+		Password:        hashedPassword,
+		IsActive:        true,
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if info.Picture != "" {
+		user.ProfileImage = &info.Picture
+	}
+
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, errors.New("failed to create user")
+	}
+
+	return user, nil
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"koda-shortlink-backend/internal/utils"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTagsPerLink = 20
+
+	// maxTagNameLength matches the tags.name column.
+	maxTagNameLength = 50
+)
+
+type TagService struct {
+	tagRepo    *repository.TagRepository
+	linkRepo   *repository.ShortLinkRepository
+	authorizer *Authorizer
+}
+
+func NewTagService(tagRepo *repository.TagRepository, linkRepo *repository.ShortLinkRepository, authorizer *Authorizer) *TagService {
+	return &TagService{
+		tagRepo:    tagRepo,
+		linkRepo:   linkRepo,
+		authorizer: authorizer,
+	}
+}
+
+// CreateTag adds a personal tag, or a workspace tag when req names a
+// workspace the user can edit.
+func (s *TagService) CreateTag(userID int64, req *models.CreateTagRequest) (*models.TagResponse, error) {
+	tag := &models.Tag{UserID: &userID}
+	if req.WorkspaceID != nil {
+		if err := s.authorizer.AuthorizeWorkspace(userID, *req.WorkspaceID, PermissionEdit); err != nil {
+			return nil, err
+		}
+		tag = &models.Tag{WorkspaceID: req.WorkspaceID}
+	}
+
+	name, color, err := validateTag(req.Name, req.Color)
+	if err != nil {
+		return nil, err
+	}
+	tag.Name = name
+	tag.Color = color
+
+	if err := s.tagRepo.Create(tag); err != nil {
+		if errors.Is(err, repository.ErrTagExists) {
+			return nil, err
+		}
+		return nil, errors.New("failed to create tag")
+	}
+
+	return tag.ToResponse(), nil
+}
+
+func (s *TagService) GetTags(userID int64, workspaceID *int64) ([]models.TagResponse, error) {
+	var tags []models.Tag
+	var err error
+	if workspaceID != nil {
+		if err := s.authorizer.AuthorizeWorkspace(userID, *workspaceID, PermissionView); err != nil {
+			return nil, err
+		}
+		tags, err = s.tagRepo.FindByWorkspace(*workspaceID)
+	} else {
+		tags, err = s.tagRepo.FindByUser(userID)
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve tags")
+	}
+
+	tagResponses := make([]models.TagResponse, len(tags))
+	for i, tag := range tags {
+		tagResponses[i] = *tag.ToResponse()
+	}
+
+	return tagResponses, nil
+}
+
+func (s *TagService) UpdateTag(tagID, userID int64, req *models.UpdateTagRequest) (*models.TagResponse, error) {
+	tag, err := s.findAuthorized(tagID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, color, err := validateTag(req.Name, req.Color)
+	if err != nil {
+		return nil, err
+	}
+	tag.Name = name
+	tag.Color = color
+
+	if err := s.tagRepo.Update(tag); err != nil {
+		if errors.Is(err, repository.ErrTagExists) {
+			return nil, err
+		}
+		return nil, errors.New("failed to update tag")
+	}
+
+	return tag.ToResponse(), nil
+}
+
+// DeleteTag removes the tag from every link carrying it.
+func (s *TagService) DeleteTag(tagID, userID int64) error {
+	if _, err := s.findAuthorized(tagID, userID); err != nil {
+		return err
+	}
+
+	if err := s.tagRepo.Delete(tagID); err != nil {
+		return errors.New("failed to delete tag")
+	}
+
+	return nil
+}
+
+// SetLinkTags replaces the tags of a link. Every tag must have the same owner
+// as the link; an empty list clears them.
+func (s *TagService) SetLinkTags(shortCode string, userID int64, tagIDs []int64) ([]models.TagResponse, error) {
+	link, err := s.linkRepo.FindByShortCode(shortCode)
+	if err != nil {
+		return nil, errors.New("link not found")
+	}
+
+	if err := s.authorizer.AuthorizeLink(userID, link, PermissionEdit); err != nil {
+		return nil, err
+	}
+
+	tagIDs = uniqueIDs(tagIDs)
+	if len(tagIDs) > maxTagsPerLink {
+		return nil, fmt.Errorf("a link can have at most %d tags", maxTagsPerLink)
+	}
+
+	tags := []models.Tag{}
+	if len(tagIDs) > 0 {
+		tags, err = s.tagRepo.FindByIDs(tagIDs)
+		if err != nil {
+			return nil, errors.New("failed to retrieve tags")
+		}
+		if len(tags) != len(tagIDs) {
+			return nil, errors.New("tag not found")
+		}
+
+		for _, tag := range tags {
+			if !sameOwner(tag.UserID, tag.WorkspaceID, link.UserID, link.WorkspaceID) {
+				return nil, errors.New("tags must belong to the same owner as the link")
+			}
+		}
+	}
+
+	if err := s.tagRepo.SetLinkTags(link.ID, tagIDs); err != nil {
+		return nil, errors.New("failed to update link tags")
+	}
+
+	tagResponses := make([]models.TagResponse, len(tags))
+	for i, tag := range tags {
+		tagResponses[i] = *tag.ToResponse()
+	}
+
+	return tagResponses, nil
+}
+
+func (s *TagService) findAuthorized(tagID, userID int64) (*models.Tag, error) {
+	tag, err := s.tagRepo.FindByID(tagID)
+	if err != nil {
+		return nil, errors.New("tag not found")
+	}
+
+	if err := s.authorizer.Authorize(userID, tag.UserID, tag.WorkspaceID, PermissionEdit); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+func validateTag(name string, color *string) (string, *string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, errors.New("tag name is required")
+	}
+	if utf8.RuneCountInString(name) > maxTagNameLength {
+		return "", nil, fmt.Errorf("tag name must be at most %d characters", maxTagNameLength)
+	}
+
+	if color == nil || *color == "" {
+		return name, nil, nil
+	}
+	if !utils.IsValidHexColor(*color) {
+		return "", nil, errors.New("invalid color: must be in #RRGGBB format")
+	}
+	normalized := strings.ToLower(*color)
+
+	return name, &normalized, nil
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"koda-shortlink-backend/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	totpIssuer               = "Koda Shortlink"
+	twoFactorChallengeExpiry = 5 * time.Minute
+	maxTwoFactorAttempts     = 5
+	recoveryCodeCount        = 10
+)
+
+type TwoFactorService struct {
+	authService      *AuthService
+	userRepo         *repository.UserRepository
+	recoveryCodeRepo *repository.RecoveryCodeRepository
+	jwtUtil          *utils.JWTUtil
+	denylist         *utils.TokenDenylist
+	loginThrottle    *LoginThrottle
+	redisClient      *redis.Client
+}
+
+func NewTwoFactorService(authService *AuthService, userRepo *repository.UserRepository, recoveryCodeRepo *repository.RecoveryCodeRepository, jwtUtil *utils.JWTUtil, denylist *utils.TokenDenylist, loginThrottle *LoginThrottle, redisClient *redis.Client) *TwoFactorService {
+	return &TwoFactorService{
+		authService:      authService,
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		jwtUtil:          jwtUtil,
+		denylist:         denylist,
+		loginThrottle:    loginThrottle,
+		redisClient:      redisClient,
+	}
+}
+
+// Setup and Enable ask for the password, like Disable, so a stolen access
+// token alone cannot enroll an attacker's authenticator on the account.
+func (s *TwoFactorService) Setup(userID int64, req *models.TwoFactorSetupRequest) (*models.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	if err := checkPassword(user, req.Password); err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+
+	if err := s.userRepo.UpdateTOTP(userID, &secret, false); err != nil {
+		return nil, errors.New("failed to start two-factor setup")
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+func (s *TwoFactorService) Enable(userID int64, req *models.TwoFactorEnableRequest) (*models.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	if user.TOTPSecret == nil {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	if err := checkPassword(user, req.Password); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyTOTP(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateTOTP(userID, user.TOTPSecret, true); err != nil {
+		return nil, errors.New("failed to enable two-factor authentication")
+	}
+
+	return s.generateRecoveryCodes(userID)
+}
+
+func (s *TwoFactorService) Disable(userID int64, req *models.TwoFactorDisableRequest) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := checkPassword(user, req.Password); err != nil {
+		return err
+	}
+
+	if err := s.verifyCode(user, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateTOTP(userID, nil, false); err != nil {
+		return errors.New("failed to disable two-factor authentication")
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByUser(userID); err != nil {
+		return errors.New("failed to delete recovery codes")
+	}
+
+	return nil
+}
+
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID int64, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.verifyTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	return s.generateRecoveryCodes(userID)
+}
+
+func (s *TwoFactorService) CompleteLogin(req *models.TwoFactorLoginRequest, client *models.ClientInfo) (*models.AuthResponse, error) {
+	claims, err := s.jwtUtil.ValidateToken(req.ChallengeToken, utils.TokenTypeChallenge)
+	if err != nil {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	revoked, err := s.denylist.IsRevoked(claims)
+	if err != nil || revoked {
+		return nil, errors.New("invalid or expired challenge token")
+	}
+
+	ctx := context.Background()
+	attemptsKey := fmt.Sprintf("2fa:attempts:%s", claims.ID)
+	attempts, err := s.redisClient.Incr(ctx, attemptsKey).Result()
+	if err != nil {
+		return nil, errors.New("failed to verify code")
+	}
+	if attempts == 1 {
+		s.redisClient.Expire(ctx, attemptsKey, twoFactorChallengeExpiry)
+	}
+	if attempts > maxTwoFactorAttempts {
+		s.denylist.RevokeToken(claims.ID, claims.ExpiresAt.Time)
+		return nil, errors.New("too many attempts, please log in again")
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("account is inactive")
+	}
+
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	// The per-challenge limit alone does not stop guessing, since knowing the
+	// password is enough to request new challenges. Failures therefore count
+	// towards the same per-account and per-IP lockout as wrong passwords.
+	if err := s.loginThrottle.Check(user.Email, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyCode(user, req.Code); err != nil {
+		s.loginThrottle.RecordFailure(user.Email, client.IPAddress)
+		return nil, err
+	}
+
+	if err := s.denylist.RevokeToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, errors.New("failed to complete login")
+	}
+
+	s.loginThrottle.Reset(user.Email)
+
+	return s.authService.createAuthResponse(user, client)
+}
+
+func (s *TwoFactorService) verifyCode(user *models.User, code string) error {
+	if err := s.verifyTOTP(user, code); err == nil {
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return errors.New("invalid verification code")
+	}
+
+	if err := s.recoveryCodeRepo.Use(user.ID, utils.HashToken(normalized)); err != nil {
+		return errors.New("invalid verification code")
+	}
+
+	return nil
+}
+
+func (s *TwoFactorService) verifyTOTP(user *models.User, code string) error {
+	if user.TOTPSecret == nil {
+		return errors.New("invalid verification code")
+	}
+
+	step, ok := utils.ValidateTOTP(*user.TOTPSecret, code, time.Now())
+	if !ok {
+		return errors.New("invalid verification code")
+	}
+
+	ctx := context.Background()
+	usedKey := fmt.Sprintf("2fa:used:%d:%d", user.ID, step)
+	fresh, err := s.redisClient.SetNX(ctx, usedKey, 1, 3*time.Minute).Result()
+	if err != nil {
+		return errors.New("failed to verify code")
+	}
+	if !fresh {
+		return errors.New("verification code has already been used")
+	}
+
+	return nil
+}
+
+func (s *TwoFactorService) generateRecoveryCodes(userID int64) (*models.RecoveryCodesResponse, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		raw, err := utils.GenerateShortCode(10)
+		if err != nil {
+			return nil, errors.New("failed to generate recovery codes")
+		}
+		raw = strings.ToLower(raw)
+
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = utils.HashToken(raw)
+	}
+
+	if err := s.recoveryCodeRepo.ReplaceForUser(userID, hashes); err != nil {
+		return nil, errors.New("failed to store recovery codes")
+	}
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	code = strings.ReplaceAll(code, " ", "")
+	return code
+}
+
+func checkPassword(user *models.User, password string) error {
+	valid, err := utils.VerifyPassword(password, user.Password)
+	if err != nil || !valid {
+		return errors.New("password is incorrect")
+	}
+	return nil
+}
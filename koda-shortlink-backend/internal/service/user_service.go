@@ -8,11 +8,19 @@ import (
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo       *repository.UserRepository
+	sessionRepo    *repository.SessionRepository
+	denylist       *utils.TokenDenylist
+	passwordPolicy *utils.PasswordPolicy
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, denylist *utils.TokenDenylist, passwordPolicy *utils.PasswordPolicy) *UserService {
+	return &UserService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		denylist:       denylist,
+		passwordPolicy: passwordPolicy,
+	}
 }
 
 func (s *UserService) GetProfile(userID int64) (*models.UserResponse, error) {
@@ -24,6 +32,22 @@ func (s *UserService) GetProfile(userID int64) (*models.UserResponse, error) {
 	return user.ToResponse(), nil
 }
 
+// GetRole returns the current role of an active user. It is read from the
+// database on every call so demotions apply without waiting for tokens to
+// expire.
+func (s *UserService) GetRole(userID int64) (string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		return "", errors.New("account is inactive")
+	}
+
+	return user.Role, nil
+}
+
 func (s *UserService) UpdateProfile(userID int64, req *models.UpdateProfileRequest) error {
 	if !utils.IsValidEmail(req.Email) {
 		return errors.New("invalid email format")
@@ -60,15 +84,15 @@ func (s *UserService) ChangePassword(userID int64, req *models.ChangePasswordReq
 		return errors.New("passwords do not match")
 	}
 
-	if err := utils.ValidatePassword(req.NewPassword); err != nil {
-		return err
-	}
-
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return errors.New("user not found")
 	}
 
+	if err := s.passwordPolicy.Validate(req.NewPassword, user.Email, user.FullName); err != nil {
+		return err
+	}
+
 	valid, err := utils.VerifyPassword(req.CurrentPassword, user.Password)
 	if err != nil || !valid {
 		return errors.New("current password is incorrect")
@@ -83,5 +107,33 @@ func (s *UserService) ChangePassword(userID int64, req *models.ChangePasswordReq
 		return errors.New("failed to update password")
 	}
 
+	if err := s.sessionRepo.DeleteByUserExcept(userID, utils.HashToken(req.RefreshToken)); err != nil {
+		return errors.New("failed to revoke sessions")
+	}
+
+	if err := s.denylist.RevokeUser(userID); err != nil {
+		return errors.New("failed to revoke access tokens")
+	}
+
+	return nil
+}
+
+func (s *UserService) SetActive(userID int64, active bool) error {
+	if err := s.userRepo.UpdateActive(userID, active); err != nil {
+		return errors.New("user not found")
+	}
+
+	if active {
+		return nil
+	}
+
+	if err := s.sessionRepo.DeleteByUser(userID); err != nil {
+		return errors.New("failed to revoke sessions")
+	}
+
+	if err := s.denylist.RevokeUser(userID); err != nil {
+		return errors.New("failed to revoke access tokens")
+	}
+
 	return nil
 }
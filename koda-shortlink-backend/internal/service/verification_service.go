@@ -0,0 +1,261 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"koda-shortlink-backend/internal/mailer"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"koda-shortlink-backend/internal/utils"
+	"log"
+	"net/url"
+	"time"
+)
+
+const (
+	emailVerificationExpiry = 24 * time.Hour
+	passwordResetExpiry     = time.Hour
+	accountDeletionExpiry   = time.Hour
+
+	// LongestTokenExpiry is the lifetime of the longest-lived token, other
+	// than access tokens, that is checked against the denylist.
+	LongestTokenExpiry = max(emailVerificationExpiry, passwordResetExpiry, accountDeletionExpiry, twoFactorChallengeExpiry)
+)
+
+// errEmailNotVerified guards features that would let an account squatting on
+// someone else's address act in their name.
+var errEmailNotVerified = errors.New("verify your email address first")
+
+type VerificationService struct {
+	userRepo       *repository.UserRepository
+	sessionRepo    *repository.SessionRepository
+	jwtUtil        *utils.JWTUtil
+	denylist       *utils.TokenDenylist
+	mailer         mailer.Mailer
+	loginThrottle  *LoginThrottle
+	passwordPolicy *utils.PasswordPolicy
+	frontendURL    string
+}
+
+func NewVerificationService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, jwtUtil *utils.JWTUtil, denylist *utils.TokenDenylist, m mailer.Mailer, loginThrottle *LoginThrottle, passwordPolicy *utils.PasswordPolicy, frontendURL string) *VerificationService {
+	return &VerificationService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		jwtUtil:        jwtUtil,
+		denylist:       denylist,
+		mailer:         m,
+		loginThrottle:  loginThrottle,
+		passwordPolicy: passwordPolicy,
+		frontendURL:    frontendURL,
+	}
+}
+
+func (s *VerificationService) SendEmailVerification(userID int64) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email is already verified")
+	}
+
+	token, err := s.jwtUtil.GenerateToken(user.ID, user.Email, utils.TokenTypeEmailVerification, emailVerificationExpiry)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThis link expires in %s.\n",
+			user.FullName, s.link("/verify-email", token), emailVerificationExpiry),
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		return errors.New("failed to send verification email")
+	}
+
+	return nil
+}
+
+func (s *VerificationService) VerifyEmail(token string) error {
+	claims, err := s.jwtUtil.ValidateToken(token, utils.TokenTypeEmailVerification)
+	if err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || user.Email != claims.Email {
+		return errors.New("invalid or expired verification token")
+	}
+
+	if err := s.consumeToken(claims); err != nil {
+		return errors.New("invalid or expired verification token")
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	if err := s.userRepo.MarkEmailVerified(user.ID, claims.Email); err != nil {
+		return errors.New("failed to verify email")
+	}
+
+	return nil
+}
+
+// RequestPasswordReset never reports whether the email is registered, so the
+// endpoint cannot be used to enumerate accounts.
+func (s *VerificationService) RequestPasswordReset(email string) error {
+	if !utils.IsValidEmail(email) {
+		return errors.New("invalid email format")
+	}
+
+	user, err := s.userRepo.FindByEmail(email)
+	if err != nil || !user.IsActive {
+		return nil
+	}
+
+	token, err := s.jwtUtil.GenerateToken(user.ID, user.Email, utils.TokenTypePasswordReset, passwordResetExpiry)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThis link expires in %s. If you did not request a reset, you can ignore this email.\n",
+			user.FullName, s.link("/reset-password", token), passwordResetExpiry),
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v", user.ID, err)
+		return errors.New("failed to send password reset email")
+	}
+
+	return nil
+}
+
+func (s *VerificationService) ResetPassword(req *models.ResetPasswordRequest) error {
+	if req.NewPassword != req.ConfirmPassword {
+		return errors.New("passwords do not match")
+	}
+
+	claims, err := s.jwtUtil.ValidateToken(req.Token, utils.TokenTypePasswordReset)
+	if err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || user.Email != claims.Email {
+		return errors.New("invalid or expired reset token")
+	}
+
+	// Checked before the token is consumed so a rejected password does not
+	// burn the link.
+	if err := s.passwordPolicy.Validate(req.NewPassword, user.Email, user.FullName); err != nil {
+		return err
+	}
+
+	if err := s.consumeToken(claims); err != nil {
+		return errors.New("invalid or expired reset token")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		return errors.New("failed to update password")
+	}
+
+	if err := s.sessionRepo.DeleteByUser(user.ID); err != nil {
+		return errors.New("failed to revoke sessions")
+	}
+
+	if err := s.denylist.RevokeUser(user.ID); err != nil {
+		return errors.New("failed to revoke access tokens")
+	}
+
+	s.loginThrottle.Reset(user.Email)
+
+	// Receiving the reset link proves ownership of the address.
+	if user.EmailVerifiedAt == nil {
+		if err := s.userRepo.MarkEmailVerified(user.ID, user.Email); err != nil {
+			log.Printf("Failed to mark email verified for user %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// SendAccountDeletionEmail lets accounts without a known password, such as
+// those created through Google, confirm their deletion.
+func (s *VerificationService) SendAccountDeletionEmail(userID int64) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	token, err := s.jwtUtil.GenerateToken(user.ID, user.Email, utils.TokenTypeAccountDeletion, accountDeletionExpiry)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	msg := &mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your account deletion",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to delete your account. Open the link below to confirm:\n\n%s\n\nThis link expires in %s. If you did not request this, you can ignore this email.\n",
+			user.FullName, s.link("/delete-account", token), accountDeletionExpiry),
+	}
+
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("Failed to send account deletion email to user %d: %v", user.ID, err)
+		return errors.New("failed to send account deletion email")
+	}
+
+	return nil
+}
+
+// ConfirmAccountDeletion consumes a deletion token issued to the given user.
+func (s *VerificationService) ConfirmAccountDeletion(token string, user *models.User) error {
+	claims, err := s.jwtUtil.ValidateToken(token, utils.TokenTypeAccountDeletion)
+	if err != nil || claims.UserID != user.ID || claims.Email != user.Email {
+		return errors.New("invalid or expired confirmation token")
+	}
+
+	if err := s.consumeToken(claims); err != nil {
+		return errors.New("invalid or expired confirmation token")
+	}
+
+	return nil
+}
+
+// consumeToken denylists the jti of a validated single-use token so a second
+// submission of the same link is rejected.
+func (s *VerificationService) consumeToken(claims *utils.JWTClaims) error {
+	revoked, err := s.denylist.IsRevoked(claims)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errors.New("token has already been used")
+	}
+
+	consumed, err := s.denylist.ConsumeToken(claims.ID, claims.ExpiresAt.Time)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return errors.New("token has already been used")
+	}
+
+	return nil
+}
+
+func (s *VerificationService) link(path, token string) string {
+	return fmt.Sprintf("%s%s?token=%s", s.frontendURL, path, url.QueryEscape(token))
+}
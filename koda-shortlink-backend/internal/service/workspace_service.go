@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"koda-shortlink-backend/internal/models"
+	"koda-shortlink-backend/internal/repository"
+	"strings"
+)
+
+type WorkspaceService struct {
+	workspaceRepo *repository.WorkspaceRepository
+	userRepo      *repository.UserRepository
+	linkService   *LinkService
+	authorizer    *Authorizer
+}
+
+func NewWorkspaceService(workspaceRepo *repository.WorkspaceRepository, userRepo *repository.UserRepository, linkService *LinkService, authorizer *Authorizer) *WorkspaceService {
+	return &WorkspaceService{
+		workspaceRepo: workspaceRepo,
+		userRepo:      userRepo,
+		linkService:   linkService,
+		authorizer:    authorizer,
+	}
+}
+
+func (s *WorkspaceService) CreateWorkspace(userID int64, req *models.CreateWorkspaceRequest) (*models.WorkspaceResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("workspace name is required")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, errEmailNotVerified
+	}
+
+	workspace := &models.Workspace{
+		Name:      name,
+		CreatedBy: &userID,
+	}
+
+	if err := s.workspaceRepo.Create(workspace); err != nil {
+		return nil, errors.New("failed to create workspace")
+	}
+
+	return workspace.ToResponse(models.WorkspaceRoleOwner), nil
+}
+
+func (s *WorkspaceService) GetWorkspaces(userID int64) ([]models.WorkspaceResponse, error) {
+	workspaces, err := s.workspaceRepo.FindByUser(userID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve workspaces")
+	}
+
+	return workspaces, nil
+}
+
+func (s *WorkspaceService) GetWorkspace(workspaceID, userID int64) (*models.WorkspaceResponse, error) {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil {
+		return nil, errors.New("workspace not found")
+	}
+
+	role, err := s.workspaceRepo.GetMemberRole(workspaceID, userID)
+	if err != nil {
+		return nil, errors.New("workspace not found")
+	}
+
+	return workspace.ToResponse(role), nil
+}
+
+func (s *WorkspaceService) UpdateWorkspace(workspaceID, userID int64, req *models.UpdateWorkspaceRequest) (*models.WorkspaceResponse, error) {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionManage); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("workspace name is required")
+	}
+
+	workspace, err := s.workspaceRepo.FindByID(workspaceID)
+	if err != nil {
+		return nil, errors.New("workspace not found")
+	}
+
+	workspace.Name = name
+	if err := s.workspaceRepo.Update(workspace); err != nil {
+		return nil, errors.New("failed to update workspace")
+	}
+
+	return workspace.ToResponse(models.WorkspaceRoleOwner), nil
+}
+
+// DeleteWorkspace removes the workspace along with all of its links.
+func (s *WorkspaceService) DeleteWorkspace(workspaceID, userID int64) error {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionManage); err != nil {
+		return err
+	}
+
+	shortCodes, err := s.workspaceRepo.Delete(workspaceID)
+	if err != nil {
+		return errors.New("failed to delete workspace")
+	}
+
+	s.linkService.EvictDestinations(shortCodes)
+
+	return nil
+}
+
+func (s *WorkspaceService) GetMembers(workspaceID, userID int64) ([]models.WorkspaceMemberResponse, error) {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionView); err != nil {
+		return nil, err
+	}
+
+	members, err := s.workspaceRepo.FindMembers(workspaceID)
+	if err != nil {
+		return nil, errors.New("failed to retrieve members")
+	}
+
+	memberResponses := make([]models.WorkspaceMemberResponse, len(members))
+	for i, member := range members {
+		memberResponses[i] = *member.ToResponse()
+	}
+
+	return memberResponses, nil
+}
+
+func (s *WorkspaceService) AddMember(workspaceID, userID int64, req *models.AddWorkspaceMemberRequest) error {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionManage); err != nil {
+		return err
+	}
+
+	if !models.IsValidWorkspaceRole(req.Role) {
+		return errors.New("invalid role: must be owner, editor or viewer")
+	}
+
+	member, err := s.userRepo.FindByEmail(req.Email)
+	if err != nil || !member.IsActive {
+		return errors.New("user not found")
+	}
+	if member.EmailVerifiedAt == nil {
+		return errors.New("user has not verified their email address")
+	}
+
+	return s.workspaceRepo.AddMember(workspaceID, member.ID, req.Role)
+}
+
+func (s *WorkspaceService) UpdateMemberRole(workspaceID, userID, memberID int64, req *models.UpdateWorkspaceMemberRequest) error {
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, PermissionManage); err != nil {
+		return err
+	}
+
+	if !models.IsValidWorkspaceRole(req.Role) {
+		return errors.New("invalid role: must be owner, editor or viewer")
+	}
+
+	return s.workspaceRepo.UpdateMemberRole(workspaceID, memberID, req.Role)
+}
+
+// RemoveMember lets owners remove anyone and every member leave on their own.
+func (s *WorkspaceService) RemoveMember(workspaceID, userID, memberID int64) error {
+	permission := PermissionManage
+	if memberID == userID {
+		permission = PermissionView
+	}
+
+	if err := s.authorizer.AuthorizeWorkspace(userID, workspaceID, permission); err != nil {
+		return err
+	}
+
+	return s.workspaceRepo.RemoveMember(workspaceID, memberID)
+}
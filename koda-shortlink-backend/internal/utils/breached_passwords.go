@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+)
+
+const breachedHashPrefixLength = 5
+
+// BreachedPasswordList looks passwords up in a local copy of a breached
+// password corpus such as the Pwned Passwords "ordered by hash" download:
+// one uppercase SHA-1 hash per line, optionally followed by ":count", sorted
+// by hash. Lookups mirror the k-anonymity range API: the file is binary
+// searched for the first hash sharing the 5 character prefix, and only that
+// range is scanned for the suffix, so the list never has to fit in memory.
+type BreachedPasswordList struct {
+	file *os.File
+	size int64
+}
+
+func OpenBreachedPasswordList(path string) (*BreachedPasswordList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &BreachedPasswordList{file: file, size: info.Size()}, nil
+}
+
+func (l *BreachedPasswordList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedHashPrefixLength], hash[breachedHashPrefixLength:]
+
+	start, err := l.rangeStart(prefix)
+	if err != nil {
+		return false, err
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(l.file, start, l.size-start))
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			key := hashKey(line)
+			if strings.HasPrefix(key, prefix) {
+				if key[breachedHashPrefixLength:] == suffix {
+					return true, nil
+				}
+			} else if key > prefix {
+				return false, nil
+			}
+		}
+
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+func (l *BreachedPasswordList) Close() error {
+	return l.file.Close()
+}
+
+// rangeStart returns the offset of the first line whose hash is not less
+// than prefix. Any offset maps to the line starting at or after it, and the
+// search narrows the byte range whose first line is the answer.
+func (l *BreachedPasswordList) rangeStart(prefix string) (int64, error) {
+	lo, hi := int64(0), l.size
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := l.lineAt(mid)
+		if err != nil {
+			return 0, err
+		}
+		if line == "" {
+			hi = mid
+			continue
+		}
+
+		if hashKey(line) < prefix {
+			lo = start + int64(len(line))
+		} else {
+			hi = mid
+		}
+	}
+
+	start, _, err := l.lineAt(lo)
+	return start, err
+}
+
+// lineAt returns the first line starting at or after offset, including its
+// trailing newline. An empty line means offset is past the last line.
+func (l *BreachedPasswordList) lineAt(offset int64) (int64, string, error) {
+	start := offset
+	if offset > 0 {
+		reader := bufio.NewReader(io.NewSectionReader(l.file, offset-1, l.size-offset+1))
+		skipped, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return l.size, "", nil
+		}
+		if err != nil {
+			return 0, "", err
+		}
+		start = offset - 1 + int64(len(skipped))
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(l.file, start, l.size-start))
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return 0, "", err
+	}
+
+	return start, line, nil
+}
+
+func hashKey(line string) string {
+	if key, _, found := strings.Cut(line, ":"); found {
+		line = key
+	}
+	return strings.ToUpper(strings.TrimSpace(line))
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type SigningKey struct {
+	ID          string
+	Method      jwt.SigningMethod
+	PrivateKey  crypto.Signer
+	PublicKey   crypto.PublicKey
+	ActivatesAt time.Time
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadSigningKeys parses a comma separated list of "kid=/path/to/key.pem@activation"
+// entries, where activation is an RFC3339 timestamp and may be omitted for keys
+// that are active immediately. Keys are returned ordered by activation time.
+func LoadSigningKeys(spec string) ([]*SigningKey, error) {
+	var keys []*SigningKey
+	seen := make(map[string]bool)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kid, rest, ok := strings.Cut(entry, "=")
+		if !ok || kid == "" || rest == "" {
+			return nil, fmt.Errorf("invalid signing key entry %q", entry)
+		}
+		if seen[kid] {
+			return nil, fmt.Errorf("duplicate signing key id %q", kid)
+		}
+		seen[kid] = true
+
+		path, activation, hasActivation := strings.Cut(rest, "@")
+
+		var activatesAt time.Time
+		if hasActivation {
+			parsed, err := time.Parse(time.RFC3339, activation)
+			if err != nil {
+				return nil, fmt.Errorf("invalid activation time for key %q: %w", kid, err)
+			}
+			activatesAt = parsed
+		}
+
+		key, err := loadSigningKey(kid, path)
+		if err != nil {
+			return nil, err
+		}
+		key.ActivatesAt = activatesAt
+
+		keys = append(keys, key)
+	}
+
+	sort.SliceStable(keys, func(i, k int) bool {
+		return keys[i].ActivatesAt.Before(keys[k].ActivatesAt)
+	})
+
+	return keys, nil
+}
+
+func loadSigningKey(kid, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading signing key %q: %w", kid, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %q is not PEM encoded", kid)
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q for signing key %q", block.Type, kid)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing signing key %q: %w", kid, err)
+	}
+
+	switch privateKey := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{
+			ID:         kid,
+			Method:     jwt.SigningMethodRS256,
+			PrivateKey: privateKey,
+			PublicKey:  &privateKey.PublicKey,
+		}, nil
+	case ed25519.PrivateKey:
+		return &SigningKey{
+			ID:         kid,
+			Method:     jwt.SigningMethodEdDSA,
+			PrivateKey: privateKey,
+			PublicKey:  privateKey.Public(),
+		}, nil
+	default:
+		return nil, errors.New("signing keys must be RSA or Ed25519")
+	}
+}
+
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+
+	switch publicKey := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+	}
+
+	return jwk
+}
@@ -6,11 +6,31 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeRefresh   = "refresh"
+	TokenTypeChallenge = "2fa_challenge"
+
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypePasswordReset     = "password_reset"
+	TokenTypeAccountDeletion   = "account_deletion"
+
+	tokenAudiencePrefix = "koda-shortlink:"
+)
+
+func init() {
+	// The denylist compares iat against user-wide revocations, which needs
+	// finer timestamps than the default whole seconds.
+	jwt.TimePrecision = time.Millisecond
+}
+
 type JWTClaims struct {
-	UserID int64  `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    int64  `json:"user_id"`
+	Email     string `json:"email"`
+	TokenType string `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
@@ -18,6 +38,7 @@ type JWTUtil struct {
 	secret        string
 	accessExpiry  time.Duration
 	refreshExpiry time.Duration
+	signingKeys   []*SigningKey
 }
 
 func NewJWTUtil(secret string, accessExpiry, refreshExpiry time.Duration) *JWTUtil {
@@ -28,55 +49,140 @@ func NewJWTUtil(secret string, accessExpiry, refreshExpiry time.Duration) *JWTUt
 	}
 }
 
-func (j *JWTUtil) GenerateAccessToken(userID int64, email string) (string, error) {
-	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessExpiry)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
-	}
+func (j *JWTUtil) SetSigningKeys(keys []*SigningKey) {
+	j.signingKeys = keys
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secret))
+func (j *JWTUtil) GenerateAccessToken(userID int64, email string) (string, error) {
+	return j.GenerateToken(userID, email, TokenTypeAccess, j.accessExpiry)
 }
 
 func (j *JWTUtil) GenerateRefreshToken(userID int64, email string) (string, error) {
+	return j.GenerateToken(userID, email, TokenTypeRefresh, j.refreshExpiry)
+}
+
+func (j *JWTUtil) GenerateToken(userID int64, email string, tokenType string, expiry time.Duration) (string, error) {
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshExpiry)),
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{tokenAudiencePrefix + tokenType},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.secret))
+	return j.sign(claims)
 }
 
-func (j *JWTUtil) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.secret), nil
-	})
+func (j *JWTUtil) ValidateToken(tokenString string, tokenType string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, j.keyFunc,
+		jwt.WithAudience(tokenAudiencePrefix+tokenType),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if claims.TokenType != tokenType {
+		return nil, fmt.Errorf("expected %s token, got %q", tokenType, claims.TokenType)
 	}
 
-	return nil, errors.New("invalid token")
+	return claims, nil
 }
 
 func (j *JWTUtil) GetRefreshExpiry() time.Duration {
 	return j.refreshExpiry
 }
+
+func (j *JWTUtil) JWKS() *JWKSet {
+	set := &JWKSet{Keys: []JWK{}}
+	now := time.Now()
+
+	for i, key := range j.signingKeys {
+		if j.isRetired(i, now) {
+			continue
+		}
+		set.Keys = append(set.Keys, key.JWK())
+	}
+
+	return set
+}
+
+func (j *JWTUtil) sign(claims JWTClaims) (string, error) {
+	if len(j.signingKeys) == 0 {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.secret))
+	}
+
+	key := j.activeKey(time.Now())
+	if key == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+func (j *JWTUtil) keyFunc(token *jwt.Token) (interface{}, error) {
+	now := time.Now()
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if len(j.signingKeys) > 0 && j.isRetired(-1, now) {
+			return nil, errors.New("shared secret tokens are no longer accepted")
+		}
+		return []byte(j.secret), nil
+	}
+
+	for i, key := range j.signingKeys {
+		if key.ID != kid {
+			continue
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if j.isRetired(i, now) {
+			return nil, fmt.Errorf("signing key %s has been retired", kid)
+		}
+		return key.PublicKey, nil
+	}
+
+	return nil, fmt.Errorf("unknown signing key: %s", kid)
+}
+
+func (j *JWTUtil) activeKey(now time.Time) *SigningKey {
+	var active *SigningKey
+	for _, key := range j.signingKeys {
+		if key.ActivatesAt.After(now) {
+			break
+		}
+		active = key
+	}
+	return active
+}
+
+// A key keeps verifying until every token it could have signed has expired,
+// i.e. the refresh expiry after its successor became active. Index -1 stands
+// for the legacy shared secret, which is succeeded by the first key.
+func (j *JWTUtil) isRetired(index int, now time.Time) bool {
+	next := index + 1
+	if next >= len(j.signingKeys) {
+		return false
+	}
+
+	return now.After(j.signingKeys[next].ActivatesAt.Add(j.refreshExpiry))
+}
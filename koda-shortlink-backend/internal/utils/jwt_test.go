@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func newTestJWTUtil() *JWTUtil {
+	return NewJWTUtil(testSecret, 15*time.Minute, 168*time.Hour)
+}
+
+func TestValidateTokenRequiresMatchingType(t *testing.T) {
+	j := newTestJWTUtil()
+
+	tokenTypes := []string{
+		TokenTypeAccess,
+		TokenTypeRefresh,
+		TokenTypeChallenge,
+		TokenTypeEmailVerification,
+		TokenTypePasswordReset,
+		TokenTypeAccountDeletion,
+	}
+
+	for _, issued := range tokenTypes {
+		token, err := j.GenerateToken(1, "user@example.com", issued, time.Minute)
+		if err != nil {
+			t.Fatalf("GenerateToken(%s): %v", issued, err)
+		}
+
+		for _, expected := range tokenTypes {
+			claims, err := j.ValidateToken(token, expected)
+			if issued == expected {
+				if err != nil {
+					t.Errorf("%s token rejected as %s: %v", issued, expected, err)
+					continue
+				}
+				if claims.TokenType != issued || claims.UserID != 1 {
+					t.Errorf("%s token returned claims %+v", issued, claims)
+				}
+			} else if err == nil {
+				t.Errorf("%s token accepted as %s", issued, expected)
+			}
+		}
+	}
+}
+
+func TestValidateTokenRejectsRefreshTokenAsAccess(t *testing.T) {
+	j := newTestJWTUtil()
+
+	refreshToken, err := j.GenerateRefreshToken(1, "user@example.com")
+	if err != nil {
+		t.Fatalf("GenerateRefreshToken: %v", err)
+	}
+
+	if _, err := j.ValidateToken(refreshToken, TokenTypeAccess); err == nil {
+		t.Fatal("refresh token accepted as access token")
+	}
+}
+
+// Both the type claim and the audience are checked, so a token whose claims
+// disagree with each other is rejected whichever one is asked for.
+func TestValidateTokenChecksTypeAndAudience(t *testing.T) {
+	j := newTestJWTUtil()
+
+	tests := []struct {
+		name      string
+		tokenType string
+		audience  []string
+		expected  string
+	}{
+		{"refresh type with access audience", TokenTypeRefresh, []string{tokenAudiencePrefix + TokenTypeAccess}, TokenTypeAccess},
+		{"access type with refresh audience", TokenTypeAccess, []string{tokenAudiencePrefix + TokenTypeRefresh}, TokenTypeAccess},
+		{"access type without audience", TokenTypeAccess, nil, TokenTypeAccess},
+		{"access type with foreign audience", TokenTypeAccess, []string{"another-service"}, TokenTypeAccess},
+		{"missing type with access audience", "", []string{tokenAudiencePrefix + TokenTypeAccess}, TokenTypeAccess},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := signTestToken(t, JWTClaims{
+				UserID:    1,
+				Email:     "user@example.com",
+				TokenType: tt.tokenType,
+				RegisteredClaims: jwt.RegisteredClaims{
+					Audience:  tt.audience,
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+					IssuedAt:  jwt.NewNumericDate(time.Now()),
+				},
+			})
+
+			if _, err := j.ValidateToken(token, tt.expected); err == nil {
+				t.Fatalf("token accepted as %s", tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateTokenRequiresExpiry(t *testing.T) {
+	j := newTestJWTUtil()
+
+	token := signTestToken(t, JWTClaims{
+		UserID:    1,
+		TokenType: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience: jwt.ClaimStrings{tokenAudiencePrefix + TokenTypeAccess},
+		},
+	})
+
+	if _, err := j.ValidateToken(token, TokenTypeAccess); err == nil {
+		t.Fatal("token without expiry accepted")
+	}
+}
+
+func signTestToken(t *testing.T, claims JWTClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
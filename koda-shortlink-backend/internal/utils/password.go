@@ -27,6 +27,20 @@ var DefaultArgon2Params = &Argon2Params{
 	KeyLength:   32,
 }
 
+// SetDefaultArgon2Params replaces the parameters used for new hashes. It is
+// meant to be called once at startup, before any password is hashed.
+func SetDefaultArgon2Params(params *Argon2Params) error {
+	if params.Memory < 8*uint32(params.Parallelism) || params.Iterations < 1 || params.Parallelism < 1 {
+		return errors.New("invalid argon2 cost parameters")
+	}
+	if params.SaltLength < 8 || params.KeyLength < 16 {
+		return errors.New("argon2 salt must be at least 8 bytes and key at least 16 bytes")
+	}
+
+	DefaultArgon2Params = params
+	return nil
+}
+
 func HashPassword(password string) (string, error) {
 	return HashPasswordWithParams(password, DefaultArgon2Params)
 }
@@ -63,6 +77,23 @@ func VerifyPassword(password, encodedHash string) (bool, error) {
 	return false, nil
 }
 
+// NeedsRehash reports whether a hash was produced with any parameter weaker
+// than the current defaults. Hashes with stronger parameters are kept, so
+// lowering the configuration never triggers a downgrade.
+func NeedsRehash(encodedHash string) bool {
+	params, _, _, err := decodeHash(encodedHash)
+	if err != nil {
+		return false
+	}
+
+	current := DefaultArgon2Params
+	return params.Memory < current.Memory ||
+		params.Iterations < current.Iterations ||
+		params.Parallelism < current.Parallelism ||
+		params.SaltLength < current.SaltLength ||
+		params.KeyLength < current.KeyLength
+}
+
 func generateRandomBytes(n uint32) ([]byte, error) {
 	b := make([]byte, n)
 	_, err := rand.Read(b)
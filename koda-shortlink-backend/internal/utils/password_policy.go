@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Name and email fragments shorter than this are too common to reject
+// passwords for containing them.
+const minPersonalInfoLength = 4
+
+type PasswordPolicy struct {
+	MinLength            int
+	MaxLength            int
+	RequireUppercase     bool
+	RequireLowercase     bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	DisallowPersonalInfo bool
+	Breached             *BreachedPasswordList
+}
+
+// Validate checks a new password against the policy. personalInfo holds the
+// account's email and full name, which the password must not contain.
+func (p *PasswordPolicy) Validate(password string, personalInfo ...string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+
+	// Argon2 cost grows with input size, so the limit is in bytes.
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("password must be at most %d bytes long", p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUppercase && !hasUpper {
+		return errors.New("password must contain an uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		return errors.New("password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		return errors.New("password must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return errors.New("password must contain a symbol")
+	}
+
+	if p.DisallowPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		return errors.New("password must not contain your name or email")
+	}
+
+	if p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			log.Printf("Failed to check breached password list: %v", err)
+		} else if breached {
+			return errors.New("password has appeared in a data breach, please choose a different one")
+		}
+	}
+
+	return nil
+}
+
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	lower := strings.ToLower(password)
+
+	for _, info := range personalInfo {
+		info = strings.ToLower(strings.TrimSpace(info))
+		if info == "" {
+			continue
+		}
+
+		if local, _, found := strings.Cut(info, "@"); found {
+			info = local
+		}
+
+		parts := strings.FieldsFunc(info, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= minPersonalInfoLength && strings.Contains(lower, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
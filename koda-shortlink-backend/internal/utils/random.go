@@ -0,0 +1,13 @@
+package utils
+
+import (
+	"encoding/base64"
+)
+
+func GenerateRandomString(byteLength uint32) (string, error) {
+	b, err := generateRandomBytes(byteLength)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
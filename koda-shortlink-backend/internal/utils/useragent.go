@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"strings"
 )
 
@@ -19,6 +21,11 @@ func ParseUserAgent(userAgent string) *DeviceInfo {
 	return info
 }
 
+func VisitorFingerprint(ipAddress, userAgent string) string {
+	sum := sha256.Sum256([]byte(ipAddress + "|" + userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
 func detectDeviceType(userAgent string) string {
 	ua := strings.ToLower(userAgent)
 
@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"errors"
 	"net/url"
 	"regexp"
 	"strings"
@@ -9,10 +8,17 @@ import (
 
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 
+var hexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
 func IsValidEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// IsValidHexColor accepts colors in the #RRGGBB form.
+func IsValidHexColor(color string) bool {
+	return hexColorRegex.MatchString(color)
+}
+
 func IsValidURL(urlString string) bool {
 	u, err := url.Parse(urlString)
 	if err != nil {
@@ -22,13 +28,6 @@ func IsValidURL(urlString string) bool {
 	return u.Scheme != "" && u.Host != ""
 }
 
-func ValidatePassword(password string) error {
-	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters long")
-	}
-	return nil
-}
-
 func ValidateStruct(data interface{}) error {
 	return nil
 }
@@ -0,0 +1,7 @@
+-- gen_random_uuid() is built into PostgreSQL 13+; older servers get it from pgcrypto.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+ALTER TABLE sessions ADD COLUMN family_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE sessions ADD COLUMN rotated_at TIMESTAMP;
+
+CREATE INDEX idx_sessions_family_id ON sessions(family_id);
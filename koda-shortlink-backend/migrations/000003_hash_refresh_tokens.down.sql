@@ -0,0 +1,11 @@
+-- Plaintext tokens cannot be recovered from their digests, so every session
+-- restored by this migration is effectively revoked.
+ALTER TABLE sessions ADD COLUMN refresh_token TEXT;
+
+UPDATE sessions SET refresh_token = refresh_token_hash;
+
+ALTER TABLE sessions ALTER COLUMN refresh_token SET NOT NULL;
+ALTER TABLE sessions ADD CONSTRAINT sessions_refresh_token_key UNIQUE (refresh_token);
+CREATE INDEX idx_sessions_refresh_token ON sessions(refresh_token);
+
+ALTER TABLE sessions DROP COLUMN refresh_token_hash;
@@ -0,0 +1,9 @@
+ALTER TABLE sessions ADD COLUMN refresh_token_hash VARCHAR(64);
+
+UPDATE sessions SET refresh_token_hash = encode(sha256(convert_to(refresh_token, 'UTF8')), 'hex');
+
+ALTER TABLE sessions ALTER COLUMN refresh_token_hash SET NOT NULL;
+ALTER TABLE sessions ADD CONSTRAINT sessions_refresh_token_hash_key UNIQUE (refresh_token_hash);
+
+DROP INDEX IF EXISTS idx_sessions_refresh_token;
+ALTER TABLE sessions DROP COLUMN refresh_token;
@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Accounts created before verification existed keep API key and workspace
+-- access; only new sign-ups have to confirm their address.
+UPDATE users SET email_verified_at = created_at;
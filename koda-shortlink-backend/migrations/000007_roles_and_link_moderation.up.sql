@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
+
+CREATE INDEX idx_users_role ON users(role);
+
+ALTER TABLE short_links ADD COLUMN blocked_at TIMESTAMP;
@@ -0,0 +1,11 @@
+DELETE FROM short_links WHERE workspace_id IS NOT NULL;
+
+ALTER TABLE short_links DROP CONSTRAINT IF EXISTS short_links_user_id_fkey;
+ALTER TABLE short_links ADD CONSTRAINT short_links_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_short_links_workspace_id;
+ALTER TABLE short_links DROP COLUMN IF EXISTS workspace_id;
+
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
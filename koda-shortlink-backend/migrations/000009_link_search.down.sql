@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_short_links_workspace_created_at;
+DROP INDEX IF EXISTS idx_short_links_user_created_at;
+
+DROP INDEX IF EXISTS idx_short_links_description_trgm;
+DROP INDEX IF EXISTS idx_short_links_title_trgm;
+DROP INDEX IF EXISTS idx_short_links_destination_trgm;
+DROP INDEX IF EXISTS idx_short_links_short_code_trgm;
@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_short_links_short_code_trgm ON short_links USING GIN (short_code gin_trgm_ops);
+CREATE INDEX idx_short_links_destination_trgm ON short_links USING GIN (destination gin_trgm_ops);
+CREATE INDEX idx_short_links_title_trgm ON short_links USING GIN (title gin_trgm_ops);
+CREATE INDEX idx_short_links_description_trgm ON short_links USING GIN (description gin_trgm_ops);
+
+-- Sorting by click_count or updated_at is left unindexed: every redirect
+-- bumps click_count, which also fires the updated_at trigger, and an index on
+-- either column would rule out HOT updates for those writes.
+CREATE INDEX idx_short_links_user_created_at ON short_links(user_id, created_at DESC);
+CREATE INDEX idx_short_links_workspace_created_at ON short_links(workspace_id, created_at DESC);
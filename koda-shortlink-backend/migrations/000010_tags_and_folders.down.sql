@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_short_links_folder_id;
+ALTER TABLE short_links DROP COLUMN IF EXISTS folder_id;
+
+DROP TRIGGER IF EXISTS update_folders_updated_at ON folders;
+DROP TABLE IF EXISTS folders;
+
+DROP TABLE IF EXISTS link_tags;
+
+DROP TRIGGER IF EXISTS update_tags_updated_at ON tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,49 @@
+-- Tags and folders belong either to a user, for their personal links, or to
+-- a workspace, exactly like the links they organize.
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    workspace_id BIGINT REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT tags_owner_check CHECK ((user_id IS NULL) <> (workspace_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_tags_user_name ON tags(user_id, LOWER(name)) WHERE workspace_id IS NULL;
+CREATE UNIQUE INDEX idx_tags_workspace_name ON tags(workspace_id, LOWER(name)) WHERE workspace_id IS NOT NULL;
+
+CREATE TRIGGER update_tags_updated_at BEFORE UPDATE ON tags
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE IF NOT EXISTS link_tags (
+    link_id BIGINT NOT NULL REFERENCES short_links(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (link_id, tag_id)
+);
+
+CREATE INDEX idx_link_tags_tag_id ON link_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS folders (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    workspace_id BIGINT REFERENCES workspaces(id) ON DELETE CASCADE,
+    parent_id BIGINT REFERENCES folders(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT folders_owner_check CHECK ((user_id IS NULL) <> (workspace_id IS NULL))
+);
+
+CREATE INDEX idx_folders_user_id ON folders(user_id);
+CREATE INDEX idx_folders_workspace_id ON folders(workspace_id);
+CREATE INDEX idx_folders_parent_id ON folders(parent_id);
+
+CREATE TRIGGER update_folders_updated_at BEFORE UPDATE ON folders
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Deleting a folder removes its subfolders; the links inside become unfiled.
+ALTER TABLE short_links ADD COLUMN folder_id BIGINT REFERENCES folders(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_short_links_folder_id ON short_links(folder_id);
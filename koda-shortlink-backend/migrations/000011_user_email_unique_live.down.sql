@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_email_live;
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
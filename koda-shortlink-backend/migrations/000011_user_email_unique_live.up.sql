@@ -0,0 +1,5 @@
+-- Soft-deleted accounts keep their row until the purge, so the address is only
+-- unique among live accounts and can sign up again during the grace period.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+
+CREATE UNIQUE INDEX idx_users_email_live ON users(email) WHERE deleted_at IS NULL;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_short_links_workspace_updated_at;
+DROP INDEX IF EXISTS idx_short_links_user_updated_at;
+
+DROP TRIGGER IF EXISTS update_short_links_updated_at ON short_links;
+
+CREATE TRIGGER update_short_links_updated_at BEFORE UPDATE ON short_links
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,14 @@
+-- Redirects only bump click_count, which should not count as an edit: keep the
+-- trigger from touching updated_at for those writes so sorting by updated_at
+-- reflects the last change to the link itself.
+DROP TRIGGER IF EXISTS update_short_links_updated_at ON short_links;
+
+CREATE TRIGGER update_short_links_updated_at BEFORE UPDATE ON short_links
+    FOR EACH ROW
+    WHEN (OLD.click_count IS NOT DISTINCT FROM NEW.click_count)
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- With clicks no longer rewriting updated_at, indexing it does not rule out
+-- HOT updates for redirects.
+CREATE INDEX idx_short_links_user_updated_at ON short_links(user_id, updated_at DESC);
+CREATE INDEX idx_short_links_workspace_updated_at ON short_links(workspace_id, updated_at DESC);